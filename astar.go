@@ -0,0 +1,89 @@
+package main
+
+import (
+	astar "github.com/beefsack/go-astar"
+)
+
+// astarSearch is shared by every node of one A* search. Cells are blocked
+// or free depending on when we reach them, so it tracks the turn we first
+// arrive on each cell.
+type astarSearch struct {
+	data    *TurnData
+	arrival map[Point]int
+}
+
+type astarNode struct {
+	Point
+	search *astarSearch
+}
+
+func (n astarNode) PathNeighbors() []astar.Pather {
+	if n.search.data.timeUp() {
+		// No neighbors ends the search as if there were no path.
+		return nil
+	}
+	turn := n.search.arrival[n.Point] + 1
+	neighbors := make([]astar.Pather, 0, 4)
+	for dir := UP; dir < num_dirs; dir++ {
+		p := n.Step(dir)
+		if !freeAt(n.search.data, p, turn) {
+			continue
+		}
+		if t, ok := n.search.arrival[p]; !ok || turn < t {
+			n.search.arrival[p] = turn
+		}
+		neighbors = append(neighbors, astarNode{Point: p, search: n.search})
+	}
+	return neighbors
+}
+
+func (n astarNode) PathNeighborCost(to astar.Pather) float64 {
+	return 1
+}
+
+func (n astarNode) PathEstimatedCost(to astar.Pather) float64 {
+	return float64(heuristic_cost(n.Point, to.(astarNode).Point))
+}
+
+// AStar finds the first move of the shortest path from our head to goal,
+// treating snake bodies as obstacles until their tails pass. found is
+// false when there is no such path.
+func AStar(data *TurnData, goal Point) (dir Dir, found bool) {
+	head := data.mysnake.Coords[0]
+	if head == goal || !data.board.Contains(goal) {
+		return UP, false
+	}
+
+	search := &astarSearch{data: data, arrival: map[Point]int{head: 0}}
+	path, _, found := astar.Path(astarNode{Point: head, search: search}, astarNode{Point: goal, search: search})
+	if !found || len(path) < 2 {
+		return UP, false
+	}
+
+	// The path runs from goal back to our head.
+	step := path[len(path)-2].(astarNode).Point
+	for dir = UP; dir < num_dirs; dir++ {
+		if head.Step(dir) == step {
+			return dir, true
+		}
+	}
+	return UP, false
+}
+
+// predictHead guesses where snake's head will be next turn: straight on if
+// it can, otherwise the first open cell next to it.
+func predictHead(data *TurnData, snake Snake) Point {
+	head := snake.Coords[0]
+	if len(snake.Coords) > 1 && snake.Coords[1] != head {
+		ahead := Point{X: 2*head.X - snake.Coords[1].X, Y: 2*head.Y - snake.Coords[1].Y}
+		if freeAt(data, ahead, 1) {
+			return ahead
+		}
+	}
+	for dir := UP; dir < num_dirs; dir++ {
+		if p := head.Step(dir); freeAt(data, p, 1) {
+			return p
+		}
+	}
+	return head
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	assert "gopkg.in/go-playground/assert.v1"
+)
+
+func TestAStar(t *testing.T) {
+	// Food is straight up but a wall is in the way; the path goes around
+	// its open right end.
+	req := &MoveRequest{
+		Width:  5,
+		Height: 5,
+		You:    "me",
+		Food:   []Point{{1, 0}},
+		Snakes: []Snake{
+			{ID: "me", Coords: []Point{{1, 3}, {1, 4}, {2, 4}}},
+			{ID: "wall", Coords: []Point{{0, 2}, {1, 2}, {2, 2}, {3, 2}, {3, 1}, {2, 1}, {1, 1}, {0, 1}}},
+		},
+	}
+	data := NewTurnData(req, time.Time{})
+
+	dir, found := AStar(data, Point{1, 0})
+	assert.Equal(t, found, true)
+	assert.Equal(t, dir, RIGHT)
+	assert.Equal(t, findFood(data), RIGHT)
+
+	_, found = AStar(data, Point{-1, 0})
+	assert.Equal(t, found, false)
+	_, found = AStar(data, Point{1, 3})
+	assert.Equal(t, found, false)
+
+	// Closed off by a body that won't clear in time: no path at all.
+	req.Snakes[1].Coords = []Point{
+		{4, 2}, {3, 2}, {2, 2}, {1, 2}, {0, 2}, {0, 1},
+		{1, 1}, {2, 1}, {3, 1}, {4, 1}, {4, 0},
+	}
+	_, found = AStar(NewTurnData(req, time.Time{}), Point{1, 0})
+	assert.Equal(t, found, false)
+}
+
+func TestPredictHead(t *testing.T) {
+	req := &MoveRequest{
+		Width:  5,
+		Height: 5,
+		Snakes: []Snake{
+			{ID: "them", Coords: []Point{{2, 2}, {2, 3}, {2, 4}}},
+			{ID: "corner", Coords: []Point{{0, 0}, {1, 0}, {2, 0}}},
+		},
+	}
+	data := NewTurnData(req, time.Time{})
+
+	assert.Equal(t, predictHead(data, req.Snakes[0]), Point{2, 1})
+	assert.Equal(t, predictHead(data, req.Snakes[1]), Point{0, 1})
+}
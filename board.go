@@ -0,0 +1,164 @@
+package main
+
+type CellType uint8
+
+const (
+	EMPTY CellType = iota
+	FOOD
+	SNAKE_HEAD
+	SNAKE_BODY
+	SNAKE_TAIL
+)
+
+func (t CellType) IsSnake() bool { return t >= SNAKE_HEAD }
+
+// Cell describes one square of the board. For snake cells, snake is the
+// owner's id and pos the segment index, 0 being the head.
+type Cell struct {
+	t     CellType
+	snake string
+	pos   int
+}
+
+type occupant struct {
+	snake string
+	pos   int
+}
+
+// Board is the grid for one turn, indexed Tiles[x][y].
+type Board struct {
+	Width  int
+	Height int
+	Tiles  [][]CellType
+
+	occupants [][]occupant
+	snakes    map[string]*Snake
+	growing   map[string]bool // ate last turn, but the tail isn't stacked yet
+}
+
+// tailStays reports whether the snake's tail will still be where it is
+// after its next move. A snake that has just eaten has its last segment
+// doubled up, or, if the engine hasn't stacked it, has its health back at
+// MAX_HEALTH; either way it grows instead of pulling its tail in.
+func tailStays(snake *Snake) bool {
+	n := len(snake.Coords)
+	if n < 2 {
+		return false
+	}
+	return snake.Coords[n-1] == snake.Coords[n-2] || snake.HealthPoints == MAX_HEALTH
+}
+
+// stackedTail reports whether the snake's last segments share a cell.
+func stackedTail(snake *Snake) bool {
+	n := len(snake.Coords)
+	return n > 1 && snake.Coords[n-1] == snake.Coords[n-2]
+}
+
+func NewBoard(req *MoveRequest) *Board {
+	board := &Board{
+		Width:     req.Width,
+		Height:    req.Height,
+		Tiles:     make([][]CellType, req.Width),
+		occupants: make([][]occupant, req.Width),
+		snakes:    map[string]*Snake{},
+		growing:   map[string]bool{},
+	}
+	for x := range board.Tiles {
+		board.Tiles[x] = make([]CellType, req.Height)
+		board.occupants[x] = make([]occupant, req.Height)
+	}
+
+	for _, food := range req.Food {
+		if board.Contains(food) {
+			board.Tiles[food.X][food.Y] = FOOD
+		}
+	}
+
+	for i := range req.Snakes {
+		snake := &req.Snakes[i]
+		board.snakes[snake.ID] = snake
+		board.growing[snake.ID] = tailStays(snake) && !stackedTail(snake)
+		// Tail first, so a head stacked on its body keeps showing as a head.
+		for pos := len(snake.Coords) - 1; pos >= 0; pos-- {
+			p := snake.Coords[pos]
+			if !board.Contains(p) {
+				continue
+			}
+			// A tail that won't move next turn is as solid as the body.
+			t := SNAKE_BODY
+			if pos == 0 {
+				t = SNAKE_HEAD
+			} else if pos == len(snake.Coords)-1 && !tailStays(snake) {
+				t = SNAKE_TAIL
+			}
+			board.Tiles[p.X][p.Y] = t
+			board.occupants[p.X][p.Y] = occupant{snake: snake.ID, pos: pos}
+		}
+	}
+	return board
+}
+
+func (b *Board) Inside(x, y int) bool {
+	return x >= 0 && y >= 0 && x < b.Width && y < b.Height
+}
+
+func (b *Board) Contains(p Point) bool { return b.Inside(p.X, p.Y) }
+
+// Cell looks up p, which must be on the board.
+func (b *Board) Cell(p Point) Cell {
+	o := b.occupants[p.X][p.Y]
+	return Cell{t: b.Tiles[p.X][p.Y], snake: o.snake, pos: o.pos}
+}
+
+// Neighbors returns the points next to p that are on the board.
+func (b *Board) Neighbors(p Point) []Point {
+	neighbors := make([]Point, 0, 4)
+	for dir := UP; dir < num_dirs; dir++ {
+		if n := p.Step(dir); b.Contains(n) {
+			neighbors = append(neighbors, n)
+		}
+	}
+	return neighbors
+}
+
+// Snake returns the snake with the given id, or nil.
+func (b *Board) Snake(id string) *Snake {
+	return b.snakes[id]
+}
+
+// Length of the snake with the given id, 0 if it isn't on the board.
+func (b *Board) Length(id string) int {
+	if snake := b.snakes[id]; snake != nil {
+		return len(snake.Coords)
+	}
+	return 0
+}
+
+// Vacates is the number of moves until p is empty: 0 if it is empty now,
+// otherwise how long until its snake's tail has moved past it.
+func (b *Board) Vacates(p Point) int {
+	if !b.Contains(p) || !b.Tiles[p.X][p.Y].IsSnake() {
+		return 0
+	}
+	o := b.occupants[p.X][p.Y]
+	turns := b.Length(o.snake) - o.pos
+	if b.growing[o.snake] {
+		turns++
+	}
+	return turns
+}
+
+// Occupied returns the cells the snake with the given id covers.
+func (b *Board) Occupied(id string) []Point {
+	points := []Point{}
+	if snake := b.snakes[id]; snake != nil {
+		seen := map[Point]bool{}
+		for _, p := range snake.Coords {
+			if b.Contains(p) && !seen[p] {
+				seen[p] = true
+				points = append(points, p)
+			}
+		}
+	}
+	return points
+}
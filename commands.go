@@ -2,27 +2,12 @@ package main
 
 import (
 	"encoding/json"
-	//astar "github.com/beefsack/go-astar"
 	"fmt"
 	"log"
 	"net/http"
 	"time"
 )
 
-type CellType uint8
-
-const (
-	EMPTY CellType = iota
-	SNAKE
-	FOOD
-)
-
-type Cell struct {
-	t     CellType
-	snake string
-	pos   int
-}
-
 type Dir int8
 
 const (
@@ -40,121 +25,59 @@ var directions = [4]string{
 	RIGHT: "right",
 }
 
-type TurnData struct {
-	req     *MoveRequest
-	board   [][]Cell
-	mysnake *Snake
+var offsets = [4]Point{
+	UP:    {0, -1},
+	DOWN:  {0, 1},
+	LEFT:  {-1, 0},
+	RIGHT: {1, 0},
 }
 
-func abs(i int) int {
-	if i < 0 {
-		return -i
-	} else {
-		return i
-	}
-}
-
-func heuristic_cost(start Point, end Point) int {
-	return abs(start.X-end.X) + abs(start.Y-end.Y)
-
-}
+func (d Dir) Opposite() Dir { return (d + 2) % num_dirs }
 
-/*type Path struct {
-	p             Point
-	g             [][]Cell
-	height, width int
+// Step returns the point one move away in the given direction.
+func (p Point) Step(dir Dir) Point {
+	return Point{X: p.X + offsets[dir].X, Y: p.Y + offsets[dir].Y}
 }
 
-func (p *Path) PathNeighbors() []astar.Pather {
-	neighbors := make([]astar.Pather, 0, 4)
-	if p.p.X > 0 && p.g[p.p.Y][p.p.X-1].t != SNAKE {
-		neighbors = append(neighbors, &Path{p: Point{X: p.p.X - 1, Y: p.p.Y}, g: p.g, height: p.height, width: p.width})
-	}
-	if p.p.X < p.width-1 && p.g[p.p.Y][p.p.X+1].t != SNAKE {
-		neighbors = append(neighbors, &Path{p: Point{X: p.p.X + 1, Y: p.p.Y}, g: p.g, height: p.height, width: p.width})
-	}
-	if p.p.Y > 0 && p.g[p.p.Y-1][p.p.X].t != SNAKE {
-		neighbors = append(neighbors, &Path{p: Point{X: p.p.X, Y: p.p.Y - 1}, g: p.g, height: p.height, width: p.width})
-	}
-	if p.p.Y > p.height-1 && p.g[p.p.Y+1][p.p.X].t != SNAKE {
-		neighbors = append(neighbors, &Path{p: Point{X: p.p.X, Y: p.p.Y + 1}, g: p.g, height: p.height, width: p.width})
+func parseDir(move string) (Dir, bool) {
+	for dir, name := range directions {
+		if name == move {
+			return Dir(dir), true
+		}
 	}
-	return neighbors
+	return UP, false
 }
 
-func (p *Path) PathNeighborCost(to astar.Pather) float64 {
-	return 1
-}
-func (p *Path) PathEstimatedCost(to astar.Pather) float64 {
-	return 1
+type TurnData struct {
+	req      *MoveRequest
+	board    *Board
+	mysnake  *Snake
+	space    []int      // reachable cells per direction, see moveSpace
+	voronoi  *Territory // see territory
+	danger   DangerMap  // see dangerMap
+	params   Params
+	deadline time.Time
+	nodes    int // fixed search budget, see searchDone
+	progress *progress
+	history  History
 }
 
-/*
-func AStar(head Point, go_to Point, data *TurnData) (Point, bool) {
-	start := &Path{p: head, g: data.board, height: data.req.Height, width: data.req.Width}
-	end := &Path{p: go_to, g: data.board, height: data.req.Height, width: data.req.Width}
-	path, _, found := astar.Path(start, end)
-	if !found {
-		return Point{-1, -1}, false
-	}
-	return path[0].(*Path).p, true
-}*/
-
-/*
-func AStar(head Point, go_to Point, data TurnData) Point {
-	// Reference: https://en.wikipedia.org/wiki/A*_search_algorithm#Pseudocode
-	//Setup
-	width := data.req.Width
-	height := data.req.Height
-	closedSet := []Point{}
-	openSet := []Point{head}
-	cameFrom := []Point{}
-	// cost to get to individual point
-	// access point as go_score[i][j] = go_score[i*width + j]
-	go_score := make([]int, height*width)
-	go_score[head.Y*width+head.X] = 0
-	//cost of travelling to go_to through the point
-	through_score := make([]int, height*width)
-	through_score[head.y*width+head.x] = heuristic_cost(head, go_to)
-	// Computation
-	for len(openSet) > 0 {
-
-
-		current := through_score[0] //the node in openSet having the lowest through_score[] value
-		if current == goal {
-			return reconstruct_path(cameFrom, current)
-		}
-		openSet = append(openSet[:current], openSet[current+1:])
-		closedSet = append(current)
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	} else {
+		return i
 	}
-	return Point{-1, -1}
 }
 
-func reconstruct_path(from []Point, current Point) Point {
-	return Point{-1, -1}
-}*/
-
-func buildBoard(req *MoveRequest) (board [][]Cell) {
-	board = make([][]Cell, req.Width)
-	for i := range board {
-		board[i] = make([]Cell, req.Height)
-	}
-
-	for _, food := range req.Food {
-		board[food.Y][food.X].t = FOOD
-	}
+func heuristic_cost(start Point, end Point) int {
+	return abs(start.X-end.X) + abs(start.Y-end.Y)
 
-	for _, snake := range req.Snakes {
-		for i, body := range snake.Coords {
-			board[body.Y][body.X] = Cell{t: SNAKE, snake: snake.Id, pos: i}
-		}
-	}
-	return board
 }
 
 func getSnake(req *MoveRequest, id string) Snake {
 	for _, snake := range req.Snakes {
-		if snake.Id == id {
+		if snake.ID == id {
 			return snake
 		}
 	}
@@ -174,228 +97,164 @@ func (p *Path) Len() int {
 	return p.prev.Len() + 1
 }
 
-func (p *Path) pointInPath(point Point) bool {
-	if p.Point == point {
-		return true
-	} else if p.prev == nil {
-		return false
-	} else {
-		return p.prev.pointInPath(point)
+// first is the step from our head that starts the path.
+func (p *Path) first() *Path {
+	for p.prev.prev != nil {
+		p = p.prev
 	}
+	return p
 }
 
-func cell(board [][]Cell, p Point) *Cell {
-	return &board[p.Y][p.X]
-}
-
+// bfs heads for the nearest target: food, or when attacking the cells
+// other snakes are leaving behind them. It walks outward from our head
+// over cells that will be free by the time we get there, each by the
+// shortest way, and only takes a target that leaves us room for our body
+// once we are on it. It looks no further than BFSReach times the board's
+// width plus height.
 func bfs(data *TurnData, attack bool) Dir {
-	req := data.req
-	board := data.board
-
-	queue := make([]Path, 1, 10)
-	queue[0] = Path{Point: data.mysnake.Coords[0], prev: nil, dir: -1}
+	head := data.mysnake.Coords[0]
 
+	seen := map[Point]bool{head: true}
+	queue := []*Path{{Point: head, dir: -1}}
 	for len(queue) > 0 {
-		path := &queue[0]
-
-		if path.Len() > data.req.Width+data.req.Height {
-			found := false
-			for ; path.prev.prev != nil; path = path.prev {
-				c := cell(board, path.Point)
-				if attack {
-					if c.t == SNAKE && c.snake != data.mysnake.Id && c.pos < path.Len() {
-						found = true
-					}
-				} else {
-					if c.t == FOOD {
-						found = true
-					}
-				}
-			}
-			if found {
-				return path.dir
-			}
+		if data.timeUp() {
+			dir, _ := firstSafeDir(data)
+			return dir
 		}
+		path := queue[0]
 		queue = queue[1:]
-
-		test := Point{X: path.X, Y: path.Y - 1}
-		if path.Y > 0 && (cell(board, test).t != SNAKE || cell(board, test).pos < path.Len()) && path.dir != DOWN && !path.pointInPath(test) {
-			queue = append(queue, Path{Point: test, prev: path, dir: UP})
-		}
-		test = Point{X: path.X, Y: path.Y + 1}
-		if path.Y < req.Height-1 && (cell(board, test).t != SNAKE || cell(board, test).pos < path.Len()) && path.dir != UP && !path.pointInPath(test) {
-			queue = append(queue, Path{Point: test, prev: path, dir: DOWN})
-		}
-		test = Point{X: path.X - 1, Y: path.Y}
-		if path.X > 0 && (cell(board, test).t != SNAKE || cell(board, test).pos < path.Len()) && path.dir != RIGHT && !path.pointInPath(test) {
-			queue = append(queue, Path{Point: test, prev: path, dir: LEFT})
+		if float64(path.Len()) > data.params.BFSReach*float64(data.board.Width+data.board.Height) {
+			// The queue only gets longer paths from here.
+			break
 		}
-		test = Point{X: path.X + 1, Y: path.Y}
-		if path.X < req.Width-1 && (cell(board, test).t != SNAKE || cell(board, test).pos < path.Len()) && path.dir != LEFT && !path.pointInPath(test) {
-			queue = append(queue, Path{Point: test, prev: path, dir: RIGHT})
+
+		if path.prev != nil && bfsTarget(data, path, attack) {
+			if dir := path.first().dir; safeMove(data, dir) == 2 {
+				return dir
+			}
 		}
 
+		for _, dir := range []Dir{UP, DOWN, LEFT, RIGHT} {
+			test := path.Step(dir)
+			if !seen[test] && freeAt(data, test, path.Len()+1) {
+				seen[test] = true
+				queue = append(queue, &Path{Point: test, prev: path, dir: dir})
+			}
+		}
 	}
 
 	if attack {
 		return findEnemy(data)
-	} else {
-		return findFood(data)
 	}
+	// No food we could eat and get away from; keep our options open.
+	return roomiestDir(data)
+}
+
+// bfsTarget reports whether the end of path is worth going to.
+func bfsTarget(data *TurnData, path *Path, attack bool) bool {
+	c := data.board.Cell(path.Point)
+	if attack {
+		if !c.t.IsSnake() || c.snake == data.mysnake.ID {
+			return false
+		}
+	} else if c.t != FOOD {
+		return false
+	}
+
+	// Our body will be lying along the way we came.
+	body := map[Point]bool{}
+	for p := path.prev; p != nil; p = p.prev {
+		body[p.Point] = true
+	}
+	return reachableAvoiding(data, path.Point, path.Len(), body) >= len(data.mysnake.Coords)
 }
 
+// safeMove rates moving in dir: 0 if it runs into something or leaves us
+// boxed in, 1 if an enemy head that is at least as long could meet us
+// there or we're counting on the tail of a snake that might eat, and 2
+// otherwise. Tails that will have moved on, our own included, are free.
 func safeMove(data *TurnData, dir Dir) int {
-	req := data.req
 	board := data.board
 	myhead := data.mysnake.Coords[0]
-	mylen := len(data.mysnake.Coords)
 
-	testCells := make([]*Cell, 0, 3)
-
-	if dir == UP && myhead.Y > 0 && board[myhead.Y-1][myhead.X].t != SNAKE {
-		if myhead.X > 0 {
-			testCells = append(testCells, &board[myhead.Y-1][myhead.X-1])
-		}
-		if myhead.Y > 1 {
-			testCells = append(testCells, &board[myhead.Y-2][myhead.X])
-		}
-		if myhead.X < req.Width-1 {
-			testCells = append(testCells, &board[myhead.Y-1][myhead.X+1])
-		}
-	} else if dir == DOWN && myhead.Y < req.Height-1 && board[myhead.Y+1][myhead.X].t != SNAKE {
-		if myhead.X > 0 {
-			testCells = append(testCells, &board[myhead.Y+1][myhead.X-1])
-		}
-		if myhead.Y < req.Height-2 {
-			testCells = append(testCells, &board[myhead.Y+2][myhead.X])
-		}
-		if myhead.X < req.Width-1 {
-			testCells = append(testCells, &board[myhead.Y+1][myhead.X+1])
-		}
-	} else if dir == LEFT && myhead.X > 0 && board[myhead.Y][myhead.X-1].t != SNAKE {
-		if myhead.Y > 0 {
-			testCells = append(testCells, &board[myhead.Y-1][myhead.X-1])
-		}
-		if myhead.X > 1 {
-			testCells = append(testCells, &board[myhead.Y][myhead.X-2])
-		}
-		if myhead.Y < req.Height-1 {
-			testCells = append(testCells, &board[myhead.Y+1][myhead.X-1])
-		}
-	} else if dir == RIGHT && myhead.X < req.Width-1 && board[myhead.Y][myhead.X+1].t != SNAKE {
-		if myhead.Y > 0 {
-			testCells = append(testCells, &board[myhead.Y-1][myhead.X+1])
-		}
-		if myhead.X < req.Width-2 {
-			testCells = append(testCells, &board[myhead.Y][myhead.X+2])
-		}
-		if myhead.Y < req.Height-1 {
-			testCells = append(testCells, &board[myhead.Y+1][myhead.X+1])
-		}
-	} else {
+	dest := myhead.Step(dir)
+	if !freeAt(data, dest, 1) || cramped(data, dir) {
 		return 0
 	}
 
+	// A dead end: nowhere to go from dest on the move after.
 	all_tests := true
-	possible := false
-
-	for _, cell := range testCells {
-		if cell.t != SNAKE || cell.pos == len(getSnake(req, cell.snake).Coords)-1 {
+	for _, p := range board.Neighbors(dest) {
+		if p != myhead && freeAt(data, p, 2) {
 			all_tests = false
 		}
-		if cell.t == SNAKE && cell.pos == 0 && mylen <= len(getSnake(req, cell.snake).Coords) {
-			possible = true
-		}
 	}
-
 	if all_tests {
 		return 0
-	} else if possible {
+	}
+
+	if dangerMap(data).Worst(dest) >= HEAD_ON_TIE {
 		return 1
-	} else {
-		return 2
 	}
+	if c := board.Cell(dest); c.t == SNAKE_TAIL && c.snake != data.mysnake.ID && mightEat(data, c.snake) {
+		return 1
+	}
+	return 2
+}
+
+// mightEat reports whether the snake could reach food with its next move,
+// which would keep its tail where it is.
+func mightEat(data *TurnData, id string) bool {
+	snake := data.board.Snake(id)
+	if snake == nil || len(snake.Coords) == 0 {
+		return false
+	}
+	for _, p := range data.board.Neighbors(snake.Coords[0]) {
+		if data.board.Cell(p).t == FOOD {
+			return true
+		}
+	}
+	return false
 }
 
 func firstSafeDir(data *TurnData) (Dir, int) {
+	danger := dangerMap(data)
 	var dir Dir
-	var risky Dir
+	risky, found := UP, false
 	for dir = UP; dir < num_dirs; dir++ {
 		safety := safeMove(data, dir)
 		if safety == 2 {
 			return dir, 2
 		} else if safety == 1 {
-			risky = dir
+			// A tie kills us too, but we'd rather meet a snake that dies
+			// with us than one that survives.
+			p := data.mysnake.Coords[0].Step(dir)
+			if !found || danger.Worst(p) < danger.Worst(data.mysnake.Coords[0].Step(risky)) {
+				risky, found = dir, true
+			}
 		}
 	}
 
+	if !found {
+		// Every way is deadly; go where it might not be.
+		return survivalMove(data.req), 0
+	}
 	return risky, 1
 }
 
+// target heads for t along the shortest A* path, settling for a risky
+// first step only when nothing else is safe.
 func target(data *TurnData, t Point) Dir {
-	myhead := data.mysnake.Coords[0]
-	x_dist := myhead.X - t.X
-	y_dist := myhead.Y - t.Y
-
-	risky := Dir(-1)
-
-	if abs(x_dist) > abs(y_dist) {
-		if x_dist < 0 { // go right
-			safety := safeMove(data, RIGHT)
-			if safety == 2 {
-				return RIGHT
-			} else if safety == 1 && risky < 0 {
-				risky = RIGHT
-			}
-		}
-		if x_dist > 0 { // go left
-			safety := safeMove(data, LEFT)
-			if safety == 2 {
-				return LEFT
-			} else if safety == 1 && risky < 0 {
-				risky = LEFT
-			}
-		}
-	} else {
-		if y_dist < 0 { //go down
-			safety := safeMove(data, DOWN)
-			if safety == 2 {
-				return DOWN
-			} else if safety == 1 && risky < 0 {
-				risky = DOWN
-			}
-		}
-		if y_dist > 0 { //go up
-			safety := safeMove(data, UP)
-			if safety == 2 {
-				return UP
-			} else if safety == 1 && risky < 0 {
-				risky = UP
-			}
-		}
+	dir, found := AStar(data, t)
+	if found && safeMove(data, dir) == 2 {
+		return dir
 	}
 
-	dir, safety := firstSafeDir(data)
-	if risky >= 0 && safety == 1 {
-		return risky
-	} else {
+	safe, safety := firstSafeDir(data)
+	if found && safety < 2 && safeMove(data, dir) == 1 {
 		return dir
-	} /*
-
-		p, found := AStar(myhead, shortest, data)
-
-		if !found {
-			return firstSafeDir(data)
-		} else if p.X == myhead.X-1 {
-			return LEFT
-		} else if p.X == myhead.X+1 {
-			return RIGHT
-		} else if p.Y == myhead.Y-1 {
-			return UP
-		} else if p.Y == myhead.Y+1 {
-			return DOWN
-		}
-		return firstSafeDir(data)*/
+	}
+	return safe
 }
 
 func findFood(data *TurnData) Dir {
@@ -421,18 +280,22 @@ func findFood(data *TurnData) Dir {
 }
 
 func findEnemy(data *TurnData) Dir {
+	if dir, ok := headOnMove(data); ok {
+		return dir
+	}
+
 	shortest := Point{-1, -1}
 	snake_list := data.req.Snakes
 	myhead := data.mysnake.Coords[0]
 	short_dist := -1
 
 	for _, snake := range snake_list {
-		if snake.Id == data.mysnake.Id {
+		if snake.ID == data.mysnake.ID {
 			continue
 		}
 		dist := heuristic_cost(myhead, snake.Coords[0])
 		if dist < short_dist || short_dist == -1 {
-			shortest = snake.Coords[0]
+			shortest = predictHead(data, snake)
 			short_dist = dist
 		}
 	}
@@ -445,58 +308,146 @@ func respond(res http.ResponseWriter, obj interface{}) {
 	json.NewEncoder(res).Encode(obj)
 }
 
-func handleStart(res http.ResponseWriter, req *http.Request) {
-	scheme := "http"
-	if req.TLS != nil {
-		scheme = "https"
-	}
-	respond(res, GameStartResponse{
-		Taunt:      toStringPointer("Whoa dude"),
-		Color:      "#00FF00",
-		Name:       "Skate Fast Eat Gushers",
-		Head:       "shades",
-		Tail:       "curled",
-		Head_Image: toStringPointer(fmt.Sprintf("%v://%v/head.png", scheme, req.Host)),
+// Paths of the form /{snake}/start, /{snake}/move and /{snake}/end play as
+// the configured snake profile of that name, or, for a strategy name, as
+// the default snake with that strategy.
+func handleIndex(static http.Handler) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		_, action := splitStrategyPath(req.URL.Path)
+		if name, _ := profileFor(req.URL.Path); name == "" && req.URL.Path != "/" {
+			static.ServeHTTP(res, req)
+			return
+		}
+		switch {
+		case action == "start":
+			handleStart(res, req)
+		case action == "move":
+			recoverMove(handleMove)(res, req)
+		case action == "end":
+			handleEnd(res, req)
+		case action == "" && req.Method == http.MethodGet:
+			handleInfo(res, req)
+		default:
+			http.NotFound(res, req)
+		}
+	}
+}
+
+// handleInfo answers the v1 engine's GET / handshake.
+func handleInfo(res http.ResponseWriter, req *http.Request) {
+	_, profile := profileFor(req.URL.Path)
+	respond(res, InfoResponse{
+		APIVersion: "1",
+		Author:     "DigitalCoffee",
+		Color:      profile.Color,
+		Head:       profile.Head,
+		Tail:       profile.Tail,
 	})
 }
 
+// sessionKey tells apart our snakes when several of them are in the same
+// game.
+func sessionKey(gameId string, snake string) string {
+	if snake == "" {
+		return gameId
+	}
+	return gameId + "/" + snake
+}
+
+func handleStart(res http.ResponseWriter, req *http.Request) {
+	body, _ := readBody(req)
+	snake, profile := profileFor(req.URL.Path)
+	data, err := NewGameStartRequest(req)
+	if err != nil {
+		log.Println("Bad start request: ", err)
+	} else {
+		sessions.Start(sessionKey(data.GameId, snake))
+	}
+
+	headImage := profile.HeadImage
+	if headImage == "" {
+		scheme := "http"
+		if req.TLS != nil {
+			scheme = "https"
+		}
+		headImage = fmt.Sprintf("%v://%v/head.png", scheme, req.Host)
+	}
+	response := GameStartResponse{
+		Taunt:      toStringPointer(profile.Taunt),
+		Color:      profile.Color,
+		Name:       profile.Name,
+		Head:       profile.Head,
+		Tail:       profile.Tail,
+		Head_Image: toStringPointer(headImage),
+	}
+	respond(res, response)
+	recorder.Record(Record{Type: "start", GameId: data.GameId, Snake: snake, Time: time.Now().UTC(), Request: body, Response: response})
+}
+
+func handleEnd(res http.ResponseWriter, req *http.Request) {
+	body, _ := readBody(req)
+	snake, _ := profileFor(req.URL.Path)
+	data, err := NewMoveRequest(req)
+	if err != nil {
+		log.Println("Bad end request: ", err)
+	} else {
+		key := sessionKey(data.GameId, snake)
+		last := sessions.Get(key).History().Previous
+		sessions.End(key)
+		recorder.Record(Record{Type: "end", GameId: data.GameId, Snake: snake, Time: time.Now().UTC(), Request: body})
+		if err = recordOutcome(newOutcome(data, last)); err != nil {
+			log.Println("Can't record outcome: ", err)
+		}
+	}
+	res.WriteHeader(http.StatusOK)
+}
+
 func handleMove(res http.ResponseWriter, req *http.Request) {
 	timer := time.Now()
+	body, _ := readBody(req)
+	snake, profile := profileFor(req.URL.Path)
 	data, err := NewMoveRequest(req)
+	if err == nil && len(getSnake(data, data.You).Coords) == 0 {
+		err = fmt.Errorf("snake %q is not on the board", data.You)
+	}
 	if err != nil {
-		respond(res, MoveResponse{
-			Move:  "up",
-			Taunt: toStringPointer("can't parse this!"),
-		})
+		log.Printf("Bad move request: %v\n%s", err, body)
+		response := NewMoveResponse(data.API, directions[survivalMove(data)], toStringPointer("can't parse this!"))
+		respond(res, response)
+		recorder.Record(Record{Type: "move", GameId: data.GameId, Snake: snake, Time: timer.UTC(), Request: body, Response: response, Error: err.Error()})
 		return
 	}
 
-	snake := getSnake(data, data.You)
-	turnData := &TurnData{req: data, board: buildBoard(data), mysnake: &snake}
+	budget := moveBudget(data)
+	turnData := NewTurnData(data, timer.Add(budget))
+	turnData.params = profile.Params
+	session := sessions.Get(sessionKey(data.GameId, snake))
+	turnData.history = session.History()
 
-	attack := false
-	if snake.HealthPoints > 25 {
-		attack = true
-		for _, s := range data.Snakes {
-			if s.Id != data.You && len(s.Coords) >= len(snake.Coords) {
-				attack = false
-			}
-		}
-	}
-	var dir Dir
-	if attack {
-		dir = findEnemy(turnData)
-	} else {
-		dir = findFood(turnData)
+	name, strategy := selectStrategy(req.URL.Path)
+	dir, diagnostics, err := decide(strategy, turnData)
+	if err != nil {
+		log.Printf("Strategy %s failed: %v\n%s", name, err, RenderMove(data, dir))
 	}
-	//dir = bfs(turnData, attack)
+	session.Record(turnData, dir)
 
-	respond(res, MoveResponse{
-		Move:  directions[dir],
-		Taunt: &data.You,
-	})
+	response := NewMoveResponse(data.API, directions[dir], &data.You)
+	respond(res, response)
 	t := time.Since(timer)
-	if t >= 200*time.Millisecond {
-		log.Println("Timed out: ", t)
+	recorder.Record(Record{
+		Type:        "move",
+		GameId:      data.GameId,
+		Snake:       snake,
+		Time:        timer.UTC(),
+		Strategy:    name,
+		Request:     body,
+		Response:    response,
+		Elapsed:     t.Seconds() * 1000,
+		Diagnostics: diagnostics,
+	})
+	// The searches use all of budget, so only an answer the engine gave up
+	// on is worth a report.
+	if limit := engineTimeout(data); t >= limit {
+		log.Printf("Too late: answered after %v of %v\n%s", t, limit, RenderMove(data, dir))
 	}
 }
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Profile is how a snake looks and plays.
+type Profile struct {
+	Name      string `json:"name"`
+	Color     string `json:"color"`
+	Head      string `json:"head"`
+	Tail      string `json:"tail"`
+	Taunt     string `json:"taunt"`
+	HeadImage string `json:"head_image,omitempty"` // defaults to /head.png on this server
+	Strategy  string `json:"strategy"`
+	Params    Params `json:"params"`
+}
+
+// Config is the server's configuration: a JSON file, then environment
+// variables on top. The top-level profile is the default snake; Snakes
+// holds more, served under /{name}/, each starting from the default.
+type Config struct {
+	Port         string `json:"port"`
+	MoveDeadline string `json:"move_deadline,omitempty"` // a Go duration; empty to follow the engine's timeout
+	OutcomeFile  string `json:"outcome_file,omitempty"`
+	RecordDir    string `json:"record_dir,omitempty"`
+	Profile
+	Snakes map[string]Profile `json:"-"`
+
+	moveDeadline time.Duration
+}
+
+var DefaultConfig = Config{
+	Port: "9000",
+	Profile: Profile{
+		Name:     "Skate Fast Eat Gushers",
+		Color:    "#00FF00",
+		Head:     "shades",
+		Tail:     "curled",
+		Taunt:    "Whoa dude",
+		Strategy: DEFAULT_STRATEGY,
+		Params:   DefaultParams,
+	},
+}
+
+// config is what the program runs with, set once at startup.
+var config = DefaultConfig
+
+// ConfigErrors lists everything wrong with a configuration.
+type ConfigErrors []string
+
+func (errs ConfigErrors) Error() string {
+	return "invalid configuration:\n\t" + strings.Join(errs, "\n\t")
+}
+
+// LoadConfig reads the file at path, if any, over DefaultConfig, applies
+// the environment overrides to the default profile and validates the
+// result. A field of the wrong type keeps its default and is reported
+// along with everything else that is wrong.
+func LoadConfig(path string) (Config, error) {
+	c := DefaultConfig
+	errs := ConfigErrors{}
+	var fields map[string]json.RawMessage
+	var snakes json.RawMessage
+	if path != "" {
+		body, err := ioutil.ReadFile(path)
+		if err != nil {
+			return c, err
+		}
+		if err := json.Unmarshal(body, &fields); err != nil {
+			return c, fmt.Errorf("%s: %v", path, err)
+		}
+		// Profiles wait for the overrides; see loadSnakes.
+		snakes = fields["snakes"]
+		delete(fields, "snakes")
+		errs = append(errs, decodeFields(fields, reflect.ValueOf(&c).Elem(), "")...)
+	}
+
+	overrides := map[string]*string{
+		"PORT":             &c.Port,
+		"MOVE_DEADLINE":    &c.MoveDeadline,
+		"OUTCOME_FILE":     &c.OutcomeFile,
+		"RECORD_DIR":       &c.RecordDir,
+		"SNAKE_NAME":       &c.Name,
+		"SNAKE_COLOR":      &c.Color,
+		"SNAKE_HEAD":       &c.Head,
+		"SNAKE_TAIL":       &c.Tail,
+		"SNAKE_TAUNT":      &c.Taunt,
+		"SNAKE_HEAD_IMAGE": &c.HeadImage,
+		"STRATEGY":         &c.Strategy,
+	}
+	for name, field := range overrides {
+		if value, ok := os.LookupEnv(name); ok {
+			*field = value
+		}
+	}
+
+	if file := os.Getenv("PARAMS_FILE"); file != "" {
+		p, err := LoadParams(file)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+		c.Params = p
+	}
+	// Profiles start from the default once the overrides are in.
+	if fields != nil {
+		errs = append(errs, c.loadSnakes(snakes)...)
+	}
+	errs = append(errs, c.validate()...)
+	if len(errs) > 0 {
+		return c, errs
+	}
+	return c, nil
+}
+
+// loadSnakes reads the "snakes" object of a config file. Every profile
+// starts as a copy of the default one, environment overrides included, so
+// it only has to give what is different.
+func (c *Config) loadSnakes(raw json.RawMessage) []string {
+	c.Snakes = map[string]Profile{}
+	if raw == nil {
+		return nil
+	}
+	var snakes map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &snakes); err != nil {
+		return []string{"snakes: " + decodeError(err)}
+	}
+	errs := []string{}
+	names := make([]string, 0, len(snakes))
+	for name := range snakes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prefix := "snakes." + name + "."
+		p := c.Profile
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(snakes[name], &fields); err != nil {
+			errs = append(errs, prefix[:len(prefix)-1]+": "+decodeError(err))
+		} else {
+			errs = append(errs, decodeFields(fields, reflect.ValueOf(&p).Elem(), prefix)...)
+		}
+		c.Snakes[name] = p
+	}
+	return errs
+}
+
+// decodeFields sets the fields of the struct v from the JSON object
+// fields one at a time, so a field of the wrong type only loses itself,
+// and returns a message for each one that didn't decode and for each key
+// that isn't a field at all. Embedded structs share the object; struct
+// fields such as params are decoded the same way from their own.
+func decodeFields(fields map[string]json.RawMessage, v reflect.Value, prefix string) []string {
+	known := map[string]bool{}
+	errs := setFields(fields, v, prefix, known)
+	unknown := []string{}
+	for key := range fields {
+		if !known[key] {
+			unknown = append(unknown, prefix+key+": unknown field")
+		}
+	}
+	sort.Strings(unknown)
+	return append(errs, unknown...)
+}
+
+// setFields does the work of decodeFields for v and the structs embedded
+// in it, marking the keys they have fields for in known.
+func setFields(fields map[string]json.RawMessage, v reflect.Value, prefix string, known map[string]bool) []string {
+	errs := []string{}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		if field.Anonymous {
+			errs = append(errs, setFields(fields, v.Field(i), prefix, known)...)
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		known[name] = true
+		raw, ok := fields[name]
+		if !ok {
+			continue
+		}
+		if field.Type.Kind() == reflect.Struct {
+			var nested map[string]json.RawMessage
+			if err := json.Unmarshal(raw, &nested); err != nil {
+				errs = append(errs, prefix+name+": "+decodeError(err))
+			} else {
+				errs = append(errs, decodeFields(nested, v.Field(i), prefix+name+".")...)
+			}
+			continue
+		}
+		if err := json.Unmarshal(raw, v.Field(i).Addr().Interface()); err != nil {
+			errs = append(errs, prefix+name+": "+decodeError(err))
+		}
+	}
+	return errs
+}
+
+// decodeError words a failed field decode for ConfigErrors.
+func decodeError(err error) string {
+	if e, ok := err.(*json.UnmarshalTypeError); ok {
+		kind := e.Type.Kind().String()
+		switch e.Type.Kind() {
+		case reflect.Int, reflect.Float64:
+			kind = "number"
+		case reflect.Map, reflect.Struct:
+			kind = "object"
+		}
+		return fmt.Sprintf("%s is not %s", withArticle(e.Value), withArticle(kind))
+	}
+	return err.Error()
+}
+
+func withArticle(noun string) string {
+	if strings.IndexAny(noun[:1], "aeiou") == 0 {
+		return "an " + noun
+	}
+	return "a " + noun
+}
+
+var colorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// validate returns a message for every invalid field, and parses the ones
+// that need it.
+func (c *Config) validate() []string {
+	errs := []string{}
+	if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fmt.Sprintf("port: %q is not a port number", c.Port))
+	}
+	c.moveDeadline = 0
+	if c.MoveDeadline != "" {
+		d, err := time.ParseDuration(c.MoveDeadline)
+		if err != nil || d <= 0 {
+			errs = append(errs, fmt.Sprintf("move_deadline: %q is not a positive duration", c.MoveDeadline))
+		}
+		c.moveDeadline = d
+	}
+	errs = append(errs, c.Profile.validate("")...)
+
+	names := make([]string, 0, len(c.Snakes))
+	for name := range c.Snakes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prefix := "snakes." + name + "."
+		if !profileNamePattern.MatchString(name) || reservedNames[name] {
+			errs = append(errs, fmt.Sprintf("snakes: %q can't be used in a path", name))
+		}
+		p := c.Snakes[name]
+		errs = append(errs, p.validate(prefix)...)
+	}
+	return errs
+}
+
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// reservedNames are the paths the default snake answers on.
+var reservedNames = map[string]bool{"start": true, "move": true, "end": true}
+
+func (p *Profile) validate(prefix string) []string {
+	errs := []string{}
+	if strings.TrimSpace(p.Name) == "" {
+		errs = append(errs, prefix+"name: must not be empty")
+	}
+	if !colorPattern.MatchString(p.Color) {
+		errs = append(errs, fmt.Sprintf("%scolor: %q is not a #rrggbb color", prefix, p.Color))
+	}
+	if p.Head == "" {
+		errs = append(errs, prefix+"head: must not be empty")
+	}
+	if p.Tail == "" {
+		errs = append(errs, prefix+"tail: must not be empty")
+	}
+	if p.HeadImage != "" {
+		if u, err := url.Parse(p.HeadImage); err != nil || !u.IsAbs() {
+			errs = append(errs, fmt.Sprintf("%shead_image: %q is not an absolute URL", prefix, p.HeadImage))
+		}
+	}
+	if _, ok := lookupStrategy(p.Strategy); !ok {
+		errs = append(errs, fmt.Sprintf("%sstrategy: %q is not one of %s", prefix, p.Strategy, strings.Join(strategyNames(), ", ")))
+	}
+	if err := p.Params.Validate(); err != nil {
+		errs = append(errs, prefix+"params: "+err.Error())
+	}
+	return errs
+}
+
+// profileFor picks the snake a request path is for. /{name}/... is the
+// profile of that name, or the default profile playing the strategy of
+// that name; anything else is the default profile. name is "" for the
+// default profile.
+func profileFor(path string) (name string, p Profile) {
+	name, _ = splitStrategyPath(path)
+	if p, ok := config.Snakes[name]; ok {
+		return name, p
+	}
+	p = config.Profile
+	if _, ok := lookupStrategy(name); ok {
+		p.Strategy = name
+		return name, p
+	}
+	return "", p
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	assert "gopkg.in/go-playground/assert.v1"
+)
+
+func TestLoadConfig(t *testing.T) {
+	c, err := LoadConfig("")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, c.Profile, DefaultConfig.Profile)
+
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "snake.json")
+	body := `{"name": "Noodle", "color": "#123abc", "strategy": "minimax",
+		"move_deadline": "80ms", "params": {"attack_health": 40}}`
+	assert.Equal(t, ioutil.WriteFile(path, []byte(body), 0644), nil)
+
+	os.Setenv("SNAKE_COLOR", "#ABCDEF")
+	defer os.Unsetenv("SNAKE_COLOR")
+	c, err = LoadConfig(path)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, c.Name, "Noodle")
+	assert.Equal(t, c.Color, "#ABCDEF")
+	assert.Equal(t, c.Head, DefaultConfig.Head)
+	assert.Equal(t, c.Strategy, "minimax")
+	assert.Equal(t, c.moveDeadline, 80*time.Millisecond)
+	assert.Equal(t, c.Params.AttackHealth, 40)
+	assert.Equal(t, c.Params.AttackMargin, DefaultParams.AttackMargin)
+
+	_, err = LoadConfig(filepath.Join(dir, "missing.json"))
+	assert.NotEqual(t, err, nil)
+}
+
+func TestConfigErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "snake.json")
+	body := `{"port": "http", "name": " ", "color": "green", "head": "",
+		"head_image": "head.png", "strategy": "psychic", "move_deadline": "soon",
+		"params": {"attack_margin": 0}}`
+	assert.Equal(t, ioutil.WriteFile(path, []byte(body), 0644), nil)
+
+	_, err = LoadConfig(path)
+	errs, ok := err.(ConfigErrors)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, len(errs), 8)
+	for i, field := range []string{"port", "move_deadline", "name", "color", "head", "head_image", "strategy", "params"} {
+		assert.Equal(t, strings.HasPrefix(errs[i], field+":"), true)
+	}
+
+	// Fields of the wrong type are reported with the rest, and keep their
+	// defaults meanwhile.
+	body = `{"port": 9000, "name": "Noodle", "color": ["red"], "move_deadline": "soon",
+		"params": {"attack_health": "lots", "attack_margin": 3},
+		"snakes": {"shy": {"taunt": 7, "head": ""}, "odd": "x"}}`
+	assert.Equal(t, ioutil.WriteFile(path, []byte(body), 0644), nil)
+	c, err := LoadConfig(path)
+	errs, ok = err.(ConfigErrors)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, []string(errs), []string{
+		"port: a number is not a string",
+		"color: an array is not a string",
+		"params.attack_health: a string is not a number",
+		"snakes.odd: a string is not an object",
+		"snakes.shy.taunt: a number is not a string",
+		`move_deadline: "soon" is not a positive duration`,
+		"snakes.shy.head: must not be empty",
+	})
+	assert.Equal(t, c.Port, DefaultConfig.Port)
+	assert.Equal(t, c.Name, "Noodle")
+	assert.Equal(t, c.Params.AttackHealth, DefaultParams.AttackHealth)
+	assert.Equal(t, c.Params.AttackMargin, 3)
+
+	// So are misspelt keys, which would otherwise go unnoticed.
+	body = `{"stratgy": "mcts", "params": {"atack_health": 10}, "snakes": {"shy": {"colour": "red"}}}`
+	assert.Equal(t, ioutil.WriteFile(path, []byte(body), 0644), nil)
+	c, err = LoadConfig(path)
+	errs, ok = err.(ConfigErrors)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, []string(errs), []string{
+		"params.atack_health: unknown field",
+		"stratgy: unknown field",
+		"snakes.shy.colour: unknown field",
+	})
+	assert.Equal(t, c.Strategy, DefaultConfig.Strategy)
+
+	_, err = LoadConfig(filepath.Join(dir, "missing.json"))
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, ioutil.WriteFile(path, []byte(`{"port": `), 0644), nil)
+	_, err = LoadConfig(path)
+	_, ok = err.(ConfigErrors)
+	assert.Equal(t, ok, false)
+}
+
+func TestSnakeProfiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "snake.json")
+	body := `{"name": "Noodle", "snakes": {
+		"aggressive": {"name": "Biter", "color": "#FF0000", "strategy": "attack", "params": {"attack_health": 10}},
+		"shy": {"taunt": "..."}}}`
+	assert.Equal(t, ioutil.WriteFile(path, []byte(body), 0644), nil)
+
+	os.Setenv("SNAKE_HEAD", "fang")
+	defer os.Unsetenv("SNAKE_HEAD")
+	c, err := LoadConfig(path)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(c.Snakes), 2)
+	assert.Equal(t, c.Snakes["aggressive"].Name, "Biter")
+	assert.Equal(t, c.Snakes["aggressive"].Strategy, "attack")
+	assert.Equal(t, c.Snakes["aggressive"].Params.AttackHealth, 10)
+	assert.Equal(t, c.Snakes["aggressive"].Params.AttackMargin, DefaultParams.AttackMargin)
+	// The others come from the default profile, overrides included.
+	assert.Equal(t, c.Snakes["shy"].Name, "Noodle")
+	assert.Equal(t, c.Snakes["shy"].Taunt, "...")
+	assert.Equal(t, c.Snakes["shy"].Head, "fang")
+
+	defer func(saved Config) { config = saved }(config)
+	config = c
+	name, p := profileFor("/aggressive/move")
+	assert.Equal(t, name, "aggressive")
+	assert.Equal(t, p.Strategy, "attack")
+	name, p = profileFor("/bfs/move")
+	assert.Equal(t, name, "bfs")
+	assert.Equal(t, p.Name, "Noodle")
+	assert.Equal(t, p.Strategy, "bfs")
+	name, p = profileFor("/move")
+	assert.Equal(t, name, "")
+	assert.Equal(t, p.Name, "Noodle")
+
+	start := `{"game_id": "g", "width": 5, "height": 5}`
+	for path, want := range map[string]string{"/aggressive/start": "Biter", "/bfs/start": "Noodle"} {
+		res := httptest.NewRecorder()
+		handleIndex(http.NotFoundHandler())(res, httptest.NewRequest("POST", path, strings.NewReader(start)))
+		response := GameStartResponse{}
+		assert.Equal(t, json.Unmarshal(res.Body.Bytes(), &response), nil)
+		assert.Equal(t, response.Name, want)
+	}
+}
+
+func TestSnakeProfileErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "snake.json")
+	body := `{"snakes": {"move": {}, "a/b": {}, "ok": {"color": "red", "strategy": "psychic"}}}`
+	assert.Equal(t, ioutil.WriteFile(path, []byte(body), 0644), nil)
+
+	_, err = LoadConfig(path)
+	errs, ok := err.(ConfigErrors)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, len(errs), 4)
+	assert.Equal(t, errs[0], `snakes: "a/b" can't be used in a path`)
+	assert.Equal(t, errs[1], `snakes: "move" can't be used in a path`)
+	assert.Equal(t, strings.HasPrefix(errs[2], "snakes.ok.color:"), true)
+	assert.Equal(t, strings.HasPrefix(errs[3], "snakes.ok.strategy:"), true)
+}
@@ -0,0 +1,78 @@
+package main
+
+// HeadOn is what happens to us if we meet an enemy head in a cell. The
+// values are ordered from harmless to deadly.
+type HeadOn int
+
+const (
+	NO_HEAD_ON HeadOn = iota
+	HEAD_ON_WIN
+	HEAD_ON_TIE
+	HEAD_ON_LOSE
+)
+
+// Threat is an enemy head that could move into a cell next turn.
+type Threat struct {
+	Snake   string
+	Length  int
+	Outcome HeadOn
+}
+
+// DangerMap lists, for every cell an enemy head can reach next turn, the
+// heads that can get there.
+type DangerMap map[Point][]Threat
+
+// dangerMap works out where every enemy head could be after its next move
+// and what meeting it there would mean for us. Enemies aren't expected to
+// move off the board or into a body that will still be there. The result
+// is cached on data.
+func dangerMap(data *TurnData) DangerMap {
+	if data.danger != nil {
+		return data.danger
+	}
+	danger := DangerMap{}
+	mylen := len(data.mysnake.Coords)
+	for _, snake := range data.req.Snakes {
+		if snake.ID == data.mysnake.ID || len(snake.Coords) == 0 {
+			continue
+		}
+		outcome := HEAD_ON_TIE
+		if len(snake.Coords) < mylen {
+			outcome = HEAD_ON_WIN
+		} else if len(snake.Coords) > mylen {
+			outcome = HEAD_ON_LOSE
+		}
+		for dir := UP; dir < num_dirs; dir++ {
+			p := snake.Coords[0].Step(dir)
+			if freeAt(data, p, 1) {
+				danger[p] = append(danger[p], Threat{Snake: snake.ID, Length: len(snake.Coords), Outcome: outcome})
+			}
+		}
+	}
+	data.danger = danger
+	return danger
+}
+
+// Worst is the worst head-on we could run into at p.
+func (danger DangerMap) Worst(p Point) HeadOn {
+	worst := NO_HEAD_ON
+	for _, threat := range danger[p] {
+		if threat.Outcome > worst {
+			worst = threat.Outcome
+		}
+	}
+	return worst
+}
+
+// headOnMove looks for a safe move into a cell where we would win a
+// head-on with every snake that could meet us there.
+func headOnMove(data *TurnData) (Dir, bool) {
+	danger := dangerMap(data)
+	for dir := UP; dir < num_dirs; dir++ {
+		p := data.mysnake.Coords[0].Step(dir)
+		if danger.Worst(p) == HEAD_ON_WIN && safeMove(data, dir) == 2 {
+			return dir, true
+		}
+	}
+	return UP, false
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	assert "gopkg.in/go-playground/assert.v1"
+)
+
+func TestDangerMap(t *testing.T) {
+	board := `
+		...bb1.
+		...B...
+		.......
+		2C.A...
+		...a...
+		...0...
+		.......
+		A: me health=90
+		B: long health=90
+		C: short health=90
+	`
+	req, err := ParseBoard(board)
+	assert.Equal(t, err, nil)
+	data := NewTurnData(req, time.Time{})
+	danger := dangerMap(data)
+
+	assert.Equal(t, danger[Point{3, 2}], []Threat{{Snake: "long", Length: 4, Outcome: HEAD_ON_LOSE}})
+	assert.Equal(t, danger[Point{2, 3}], []Threat{{Snake: "short", Length: 2, Outcome: HEAD_ON_WIN}})
+	// Their own tail moves out of the way, their neck doesn't.
+	assert.Equal(t, danger.Worst(Point{0, 3}), HEAD_ON_WIN)
+	assert.Equal(t, danger.Worst(Point{3, 0}), NO_HEAD_ON)
+	assert.Equal(t, danger.Worst(Point{4, 3}), NO_HEAD_ON)
+
+	assert.Equal(t, safeMove(data, UP), 1)
+	assert.Equal(t, safeMove(data, LEFT), 2)
+	assert.Equal(t, safeMove(data, RIGHT), 2)
+	assert.Equal(t, safeMove(data, DOWN), 0)
+
+	dir, ok := headOnMove(data)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, dir, LEFT)
+	assert.Equal(t, findEnemy(data), LEFT)
+
+	// Once they are as long as us a head-on only takes us both out.
+	req, err = ParseBoard(board + "\t\tC: short length=3\n")
+	assert.Equal(t, err, nil)
+	data = NewTurnData(req, time.Time{})
+	assert.Equal(t, dangerMap(data).Worst(Point{2, 3}), HEAD_ON_TIE)
+	assert.Equal(t, safeMove(data, LEFT), 1)
+	_, ok = headOnMove(data)
+	assert.Equal(t, ok, false)
+}
+
+func TestFirstSafeDirPrefersTies(t *testing.T) {
+	// Up could meet the longer snake, left the one as long as us.
+	req, err := ParseBoard(`
+		...Bbb1
+		2......
+		cC.Aaa0
+		A: me health=90
+		B: long health=90 length=5
+		C: even health=90 length=4
+	`)
+	assert.Equal(t, err, nil)
+	data := NewTurnData(req, time.Time{})
+	assert.Equal(t, safeMove(data, UP), 1)
+	assert.Equal(t, safeMove(data, LEFT), 1)
+	dir, safety := firstSafeDir(data)
+	assert.Equal(t, safety, 1)
+	assert.Equal(t, dir, LEFT)
+}
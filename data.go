@@ -1,15 +1,26 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"io/ioutil"
 	"net/http"
 )
 
+// APIVersion identifies which engine protocol a request was sent with.
+type APIVersion uint8
+
+const (
+	API2017 APIVersion = iota
+	APIv1
+)
+
 type GameStartRequest struct {
-	GameId string `json:"game_id"`
-	Height int    `json:"height"`
-	Width  int    `json:"width"`
+	GameId string     `json:"game_id"`
+	Height int        `json:"height"`
+	Width  int        `json:"width"`
+	API    APIVersion `json:"-"`
 }
 
 type GameStartResponse struct {
@@ -21,19 +32,34 @@ type GameStartResponse struct {
 	Taunt      *string `json:"taunt,omitempty"`
 }
 
+// InfoResponse answers GET / for v1 engines.
+type InfoResponse struct {
+	APIVersion string `json:"apiversion"`
+	Author     string `json:"author,omitempty"`
+	Color      string `json:"color,omitempty"`
+	Head       string `json:"head,omitempty"`
+	Tail       string `json:"tail,omitempty"`
+	Version    string `json:"version,omitempty"`
+}
+
 type MoveRequest struct {
-	Food   []Point `json:"food"`
-	GameId string  `json:"game_id"`
-	Height int     `json:"height"`
-	Width  int     `json:"width"`
-	Turn   int     `json:"turn"`
-	Snakes []Snake `json:"snakes"`
-	You    string  `json:"you"`
+	Food    []Point    `json:"food"`
+	Hazards []Point    `json:"hazards,omitempty"`
+	GameId  string     `json:"game_id"`
+	Height  int        `json:"height"`
+	Width   int        `json:"width"`
+	Turn    int        `json:"turn"`
+	Snakes  []Snake    `json:"snakes"`
+	You     string     `json:"you"`
+	API     APIVersion `json:"-"`
+	Timeout int        `json:"-"` // milliseconds, v1 only
+	Me      *Snake     `json:"-"` // v1 only, still set once we are eliminated
 }
 
 type MoveResponse struct {
 	Move  string  `json:"move"`
 	Taunt *string `json:"taunt,omitempty"`
+	Shout *string `json:"shout,omitempty"`
 }
 
 type Point struct {
@@ -44,32 +70,269 @@ type Point struct {
 type Snake struct {
 	Coords       []Point `json:"coords"`
 	HealthPoints int     `json:"health_points"`
-	Id           string  `json:"id"`
+	ID           string  `json:"id"`
 	Name         string  `json:"name"`
 	Taunt        string  `json:"taunt"`
 }
 
-func NewMoveRequest(req *http.Request) (*MoveRequest, error) {
+// v1 wire format. The v1 engine puts y=0 at the bottom of the board, so
+// coordinates are flipped on decode to keep "up" meaning y-1 everywhere.
+type v1Ruleset struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type v1Game struct {
+	ID      string    `json:"id"`
+	Ruleset v1Ruleset `json:"ruleset"`
+	Timeout int       `json:"timeout"`
+}
+
+type v1Snake struct {
+	ID     string  `json:"id"`
+	Name   string  `json:"name"`
+	Health int     `json:"health"`
+	Body   []Point `json:"body"`
+	Head   Point   `json:"head"`
+	Length int     `json:"length"`
+	Shout  string  `json:"shout"`
+}
+
+type v1Board struct {
+	Height  int       `json:"height"`
+	Width   int       `json:"width"`
+	Food    []Point   `json:"food"`
+	Hazards []Point   `json:"hazards"`
+	Snakes  []v1Snake `json:"snakes"`
+}
+
+type v1Request struct {
+	Game  v1Game  `json:"game"`
+	Turn  int     `json:"turn"`
+	Board v1Board `json:"board"`
+	You   v1Snake `json:"you"`
+}
+
+func flipPoints(points []Point, height int) []Point {
+	flipped := make([]Point, len(points))
+	for i, p := range points {
+		flipped[i] = Point{X: p.X, Y: height - 1 - p.Y}
+	}
+	return flipped
+}
+
+func (s v1Snake) toSnake(height int) Snake {
+	return Snake{
+		Coords:       flipPoints(s.Body, height),
+		HealthPoints: s.Health,
+		ID:           s.ID,
+		Name:         s.Name,
+		Taunt:        s.Shout,
+	}
+}
+
+func newV1Snake(snake Snake, height int) v1Snake {
+	s := v1Snake{
+		ID:     snake.ID,
+		Name:   snake.Name,
+		Health: snake.HealthPoints,
+		Body:   flipPoints(snake.Coords, height),
+		Length: len(snake.Coords),
+		Shout:  snake.Taunt,
+	}
+	if len(s.Body) > 0 {
+		s.Head = s.Body[0]
+	}
+	return s
+}
+
+func newV1Request(req *MoveRequest) *v1Request {
+	v := &v1Request{
+		Game: v1Game{ID: req.GameId, Ruleset: v1Ruleset{Name: "standard"}, Timeout: req.Timeout},
+		Turn: req.Turn,
+		Board: v1Board{
+			Height:  req.Height,
+			Width:   req.Width,
+			Food:    flipPoints(req.Food, req.Height),
+			Hazards: flipPoints(req.Hazards, req.Height),
+			Snakes:  make([]v1Snake, len(req.Snakes)),
+		},
+	}
+	for i, snake := range req.Snakes {
+		v.Board.Snakes[i] = newV1Snake(snake, req.Height)
+	}
+	if req.Me != nil {
+		v.You = newV1Snake(*req.Me, req.Height)
+	} else {
+		v.You = newV1Snake(getSnake(req, req.You), req.Height)
+	}
+	return v
+}
+
+func (v *v1Request) toMoveRequest() *MoveRequest {
+	height := v.Board.Height
+	req := &MoveRequest{
+		Food:    flipPoints(v.Board.Food, height),
+		Hazards: flipPoints(v.Board.Hazards, height),
+		GameId:  v.Game.ID,
+		Height:  height,
+		Width:   v.Board.Width,
+		Turn:    v.Turn,
+		Snakes:  make([]Snake, len(v.Board.Snakes)),
+		You:     v.You.ID,
+		API:     APIv1,
+		Timeout: v.Game.Timeout,
+	}
+	me := v.You.toSnake(height)
+	req.Me = &me
+	for i, snake := range v.Board.Snakes {
+		req.Snakes[i] = snake.toSnake(height)
+	}
+	return req
+}
+
+// isV1 reports whether a request body uses the v1 layout, which nests
+// everything under a "board" object.
+func isV1(body []byte) bool {
+	var probe map[string]json.RawMessage
+	if json.Unmarshal(body, &probe) != nil {
+		return false
+	}
+	_, ok := probe["board"]
+	return ok
+}
+
+func DecodeMoveRequest(body []byte) (*MoveRequest, error) {
+	if isV1(body) {
+		decoded := v1Request{}
+		err := json.Unmarshal(body, &decoded)
+		return decoded.toMoveRequest(), err
+	}
 	decoded := MoveRequest{}
-	err := json.NewDecoder(req.Body).Decode(&decoded)
+	err := json.Unmarshal(body, &decoded)
 	return &decoded, err
 }
 
-func NewGameStartRequest(req *http.Request) (*GameStartRequest, error) {
+func DecodeGameStartRequest(body []byte) (*GameStartRequest, error) {
+	if isV1(body) {
+		decoded := v1Request{}
+		err := json.Unmarshal(body, &decoded)
+		return &GameStartRequest{
+			GameId: decoded.Game.ID,
+			Height: decoded.Board.Height,
+			Width:  decoded.Board.Width,
+			API:    APIv1,
+		}, err
+	}
 	decoded := GameStartRequest{}
-	err := json.NewDecoder(req.Body).Decode(&decoded)
+	err := json.Unmarshal(body, &decoded)
 	return &decoded, err
 }
 
+// 2017 wire format, used when we act as the engine.
+type snake2017 struct {
+	Coords       [][2]int `json:"coords"`
+	HealthPoints int      `json:"health_points"`
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Taunt        string   `json:"taunt"`
+}
+
+type request2017 struct {
+	Food   [][2]int    `json:"food"`
+	GameId string      `json:"game_id"`
+	Height int         `json:"height"`
+	Width  int         `json:"width"`
+	Turn   int         `json:"turn"`
+	Snakes []snake2017 `json:"snakes"`
+	You    string      `json:"you"`
+}
+
+func pairs(points []Point) [][2]int {
+	coords := make([][2]int, len(points))
+	for i, p := range points {
+		coords[i] = [2]int{p.X, p.Y}
+	}
+	return coords
+}
+
+// EncodeMoveRequest is the inverse of DecodeMoveRequest.
+func EncodeMoveRequest(req *MoveRequest, api APIVersion) ([]byte, error) {
+	if api == APIv1 {
+		return json.Marshal(newV1Request(req))
+	}
+	encoded := request2017{
+		Food:   pairs(req.Food),
+		GameId: req.GameId,
+		Height: req.Height,
+		Width:  req.Width,
+		Turn:   req.Turn,
+		Snakes: make([]snake2017, len(req.Snakes)),
+		You:    req.You,
+	}
+	for i, snake := range req.Snakes {
+		encoded.Snakes[i] = snake2017{
+			Coords:       pairs(snake.Coords),
+			HealthPoints: snake.HealthPoints,
+			ID:           snake.ID,
+			Name:         snake.Name,
+			Taunt:        snake.Taunt,
+		}
+	}
+	return json.Marshal(encoded)
+}
+
+func readBody(req *http.Request) ([]byte, error) {
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, err
+}
+
+func NewMoveRequest(req *http.Request) (*MoveRequest, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return &MoveRequest{}, err
+	}
+	return DecodeMoveRequest(body)
+}
+
+func NewGameStartRequest(req *http.Request) (*GameStartRequest, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return &GameStartRequest{}, err
+	}
+	return DecodeGameStartRequest(body)
+}
+
+// NewMoveResponse builds a move answer in the shape the engine expects:
+// 2017 engines read "taunt", v1 engines read "shout".
+func NewMoveResponse(api APIVersion, move string, taunt *string) MoveResponse {
+	if api == APIv1 {
+		return MoveResponse{Move: move, Shout: taunt}
+	}
+	return MoveResponse{Move: move, Taunt: taunt}
+}
+
 func (snake Snake) Head() Point { return snake.Coords[0] }
 
-// Decode [number, number] JSON array into a Point
+// Decode a [number, number] JSON array (2017) or an {"x", "y"} object (v1)
+// into a Point
 func (point *Point) UnmarshalJSON(data []byte) error {
 	var coords []int
-	json.Unmarshal(data, &coords)
-	if len(coords) != 2 {
+	if json.Unmarshal(data, &coords) == nil {
+		if len(coords) != 2 {
+			return errors.New("Bad set of coordinates: " + string(data))
+		}
+		*point = Point{X: coords[0], Y: coords[1]}
+		return nil
+	}
+	var obj struct {
+		X *int `json:"x"`
+		Y *int `json:"y"`
+	}
+	if json.Unmarshal(data, &obj) != nil || obj.X == nil || obj.Y == nil {
 		return errors.New("Bad set of coordinates: " + string(data))
 	}
-	*point = Point{X: coords[0], Y: coords[1]}
+	*point = Point{X: *obj.X, Y: *obj.Y}
 	return nil
 }
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"encoding/json"
+	"net/http/httptest"
 	"testing"
 
 	assert "gopkg.in/go-playground/assert.v1"
@@ -67,3 +69,149 @@ func TestBoardInside(t *testing.T) {
 	assert.Equal(t, board.Inside(10, 0), false)
 	assert.Equal(t, board.Inside(0, 20), false)
 }
+
+func TestBoardCells(t *testing.T) {
+	request := MoveRequest{
+		Width:  7,
+		Height: 3,
+		Snakes: []Snake{
+			{ID: "1", Coords: []Point{{6, 0}, {6, 1}, {5, 1}, {5, 2}}},
+			{ID: "new", Coords: []Point{{0, 2}, {0, 2}, {0, 2}}},
+			{ID: "fed", Coords: []Point{{2, 0}, {3, 0}, {4, 0}, {4, 0}}},
+		},
+	}
+	board := NewBoard(&request)
+
+	assert.Equal(t, board.Cell(Point{6, 0}), Cell{t: SNAKE_HEAD, snake: "1", pos: 0})
+	assert.Equal(t, board.Cell(Point{6, 1}), Cell{t: SNAKE_BODY, snake: "1", pos: 1})
+	assert.Equal(t, board.Cell(Point{5, 2}), Cell{t: SNAKE_TAIL, snake: "1", pos: 3})
+	assert.Equal(t, board.Cell(Point{1, 1}), Cell{t: EMPTY})
+
+	// A freshly spawned snake is all head, and a tail stacked after eating
+	// won't move next turn, so it isn't a tail yet.
+	assert.Equal(t, board.Cell(Point{0, 2}), Cell{t: SNAKE_HEAD, snake: "new", pos: 0})
+	assert.Equal(t, board.Cell(Point{4, 0}), Cell{t: SNAKE_BODY, snake: "fed", pos: 2})
+
+	assert.Equal(t, board.Length("1"), 4)
+	assert.Equal(t, board.Length("gone"), 0)
+	assert.Equal(t, board.Occupied("new"), []Point{{0, 2}})
+	assert.Equal(t, len(board.Occupied("fed")), 3)
+
+	assert.Equal(t, board.Neighbors(Point{6, 2}), []Point{{6, 1}, {5, 2}})
+	assert.Equal(t, len(board.Neighbors(Point{3, 1})), 4)
+}
+
+func TestBoardVacates(t *testing.T) {
+	request := MoveRequest{
+		Width:  7,
+		Height: 3,
+		Snakes: []Snake{
+			{ID: "1", HealthPoints: 50, Coords: []Point{{6, 0}, {6, 1}, {5, 1}, {5, 2}}},
+			{ID: "fed", HealthPoints: MAX_HEALTH, Coords: []Point{{2, 0}, {3, 0}, {4, 0}, {4, 0}}},
+			{ID: "unstacked", HealthPoints: MAX_HEALTH, Coords: []Point{{0, 0}, {0, 1}, {0, 2}}},
+		},
+	}
+	board := NewBoard(&request)
+
+	assert.Equal(t, board.Vacates(Point{1, 1}), 0)
+	assert.Equal(t, board.Vacates(Point{6, 0}), 4)
+	assert.Equal(t, board.Vacates(Point{5, 2}), 1)
+	assert.Equal(t, board.Vacates(Point{4, 0}), 2)
+
+	// Health back at the maximum means it ate even if the engine didn't
+	// stack the tail.
+	assert.Equal(t, board.Cell(Point{0, 2}).t, SNAKE_BODY)
+	assert.Equal(t, board.Vacates(Point{0, 2}), 2)
+	assert.Equal(t, board.Vacates(Point{0, 0}), 4)
+}
+
+func TestMoveRequestRoundTrip(t *testing.T) {
+	request := MoveRequest{
+		GameId: "game",
+		Turn:   7,
+		Width:  11,
+		Height: 9,
+		Food:   []Point{{1, 2}},
+		Snakes: []Snake{
+			{ID: "me", Name: "me", HealthPoints: 80, Coords: []Point{{3, 0}, {3, 1}, {4, 1}}},
+			{ID: "them", Name: "them", HealthPoints: 60, Coords: []Point{{8, 8}, {8, 7}, {8, 6}}},
+		},
+		You: "me",
+	}
+
+	for _, api := range []APIVersion{API2017, APIv1} {
+		body, err := EncodeMoveRequest(&request, api)
+		assert.Equal(t, err, nil)
+
+		decoded, err := DecodeMoveRequest(body)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, decoded.API, api)
+		assert.Equal(t, decoded.GameId, request.GameId)
+		assert.Equal(t, decoded.Turn, request.Turn)
+		assert.Equal(t, decoded.Width, request.Width)
+		assert.Equal(t, decoded.Height, request.Height)
+		assert.Equal(t, decoded.You, request.You)
+		assert.Equal(t, decoded.Food, request.Food)
+		assert.Equal(t, len(decoded.Snakes), len(request.Snakes))
+		for i, snake := range decoded.Snakes {
+			assert.Equal(t, snake.ID, request.Snakes[i].ID)
+			assert.Equal(t, snake.HealthPoints, request.Snakes[i].HealthPoints)
+			assert.Equal(t, snake.Coords, request.Snakes[i].Coords)
+		}
+	}
+}
+
+func TestV1Coordinates(t *testing.T) {
+	body := []byte(`{
+		"game": {"id": "g", "timeout": 500},
+		"turn": 2,
+		"board": {
+			"width": 5, "height": 5,
+			"food": [{"x": 0, "y": 4}],
+			"snakes": [{"id": "me", "health": 99, "body": [{"x": 1, "y": 0}, {"x": 1, "y": 1}]}]
+		},
+		"you": {"id": "me", "health": 99, "body": [{"x": 1, "y": 0}, {"x": 1, "y": 1}]}
+	}`)
+	req, err := DecodeMoveRequest(body)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, req.API, APIv1)
+	assert.Equal(t, req.Timeout, 500)
+	// v1 counts y from the bottom of the board
+	assert.Equal(t, req.Food, []Point{{0, 0}})
+	assert.Equal(t, req.Snakes[0].Coords, []Point{{1, 4}, {1, 3}})
+	assert.Equal(t, req.Me.ID, "me")
+}
+
+func TestIsV1(t *testing.T) {
+	assert.Equal(t, isV1([]byte(`{"game": {"id": "g"}, "board": {"width": 5}}`)), true)
+	assert.Equal(t, isV1([]byte(`{"game_id": "g", "width": 5, "snakes": []}`)), false)
+	assert.Equal(t, isV1([]byte(`not json`)), false)
+}
+
+func TestDecodeGameStartRequest(t *testing.T) {
+	start, err := DecodeGameStartRequest([]byte(`{"game": {"id": "g"}, "board": {"width": 7, "height": 5}}`))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, *start, GameStartRequest{GameId: "g", Width: 7, Height: 5, API: APIv1})
+
+	start, err = DecodeGameStartRequest([]byte(`{"game_id": "g", "width": 7, "height": 5}`))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, *start, GameStartRequest{GameId: "g", Width: 7, Height: 5, API: API2017})
+}
+
+func TestNewMoveResponse(t *testing.T) {
+	taunt := "hi"
+	body, _ := json.Marshal(NewMoveResponse(APIv1, "left", &taunt))
+	assert.Equal(t, string(body), `{"move":"left","shout":"hi"}`)
+	body, _ = json.Marshal(NewMoveResponse(API2017, "left", &taunt))
+	assert.Equal(t, string(body), `{"move":"left","taunt":"hi"}`)
+}
+
+func TestInfoHandshake(t *testing.T) {
+	res := httptest.NewRecorder()
+	handleIndex(nil)(res, httptest.NewRequest("GET", "/", nil))
+	info := InfoResponse{}
+	assert.Equal(t, json.Unmarshal(res.Body.Bytes(), &info), nil)
+	assert.Equal(t, info.APIVersion, "1")
+	assert.Equal(t, info.Color, config.Color)
+	assert.Equal(t, info.Head, config.Head)
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ENGINE_2017_TIMEOUT is how long the 2017 engine waits for a move.
+const ENGINE_2017_TIMEOUT = 200 * time.Millisecond
+
+// DEFAULT_MOVE_BUDGET keeps us under ENGINE_2017_TIMEOUT.
+const DEFAULT_MOVE_BUDGET = 150 * time.Millisecond
+
+// NETWORK_MARGIN is what we leave of a v1 engine's timeout for the round trip.
+const NETWORK_MARGIN = 100 * time.Millisecond
+
+// moveBudget is how long we may think about req: the engine's timeout
+// minus NETWORK_MARGIN, or the configured move deadline if that is
+// shorter.
+func moveBudget(req *MoveRequest) time.Duration {
+	budget := DEFAULT_MOVE_BUDGET
+	if req.Timeout > 0 {
+		budget = time.Duration(req.Timeout)*time.Millisecond - NETWORK_MARGIN
+		if budget < 10*time.Millisecond {
+			budget = 10 * time.Millisecond
+		}
+	}
+	if config.moveDeadline > 0 && (req.Timeout == 0 || config.moveDeadline < budget) {
+		return config.moveDeadline
+	}
+	return budget
+}
+
+// engineTimeout is how long the engine waits for our answer to req before
+// it moves us on its own.
+func engineTimeout(req *MoveRequest) time.Duration {
+	if req.Timeout > 0 {
+		return time.Duration(req.Timeout) * time.Millisecond
+	}
+	return ENGINE_2017_TIMEOUT
+}
+
+func NewTurnData(req *MoveRequest, deadline time.Time) *TurnData {
+	snake := getSnake(req, req.You)
+	return &TurnData{
+		req:      req,
+		board:    NewBoard(req),
+		mysnake:  &snake,
+		deadline: deadline,
+		progress: &progress{},
+		params:   config.Params,
+	}
+}
+
+// timeUp reports whether searches should stop and go with what they have.
+func (data *TurnData) timeUp() bool {
+	return !data.deadline.IsZero() && time.Now().After(data.deadline)
+}
+
+// searchDone reports whether a search that has been through n nodes or
+// iterations should stop: at the deadline, or once it has spent a fixed
+// budget. A fixed budget makes a search play the same move on the same
+// position however busy the machine is.
+func (data *TurnData) searchDone(n int) bool {
+	return data.timeUp() || data.nodes > 0 && n >= data.nodes
+}
+
+// progress holds the best move a strategy has found so far, so there is
+// something better than the fallback to answer with when time runs out,
+// and the plan it wants to keep for next turn.
+type progress struct {
+	sync.Mutex
+	dir  Dir
+	set  bool
+	plan interface{}
+}
+
+// Suggest records dir as the best move found so far.
+func (data *TurnData) Suggest(dir Dir) {
+	if data.progress == nil {
+		return
+	}
+	data.progress.Lock()
+	defer data.progress.Unlock()
+	data.progress.dir, data.progress.set = dir, true
+}
+
+func (data *TurnData) suggested() (Dir, bool) {
+	if data.progress == nil {
+		return UP, false
+	}
+	data.progress.Lock()
+	defer data.progress.Unlock()
+	return data.progress.dir, data.progress.set
+}
+
+// SavePlan keeps plan in the game's session; the next turn finds it in
+// History.Plan.
+func (data *TurnData) SavePlan(plan interface{}) {
+	if data.progress == nil {
+		return
+	}
+	data.progress.Lock()
+	defer data.progress.Unlock()
+	data.progress.plan = plan
+}
+
+func (data *TurnData) savedPlan() interface{} {
+	if data.progress == nil {
+		return nil
+	}
+	data.progress.Lock()
+	defer data.progress.Unlock()
+	return data.progress.plan
+}
+
+type decision struct {
+	dir         Dir
+	diagnostics Diagnostics
+	err         error
+}
+
+// decide runs strategy against data's deadline. If the strategy fails or
+// is still thinking when the deadline passes, it answers with the best
+// move suggested so far, or else a safe move worked out before the
+// strategy started, or else if even that isn't ready a survivalMove.
+func decide(strategy Strategy, data *TurnData) (Dir, Diagnostics, error) {
+	var timeout <-chan time.Time
+	if !data.deadline.IsZero() {
+		timer := time.NewTimer(time.Until(data.deadline))
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	fallback := make(chan Dir, 1)
+	result := make(chan decision, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				result <- decision{err: fmt.Errorf("panic: %v", r)}
+			}
+		}()
+		// Worked out first so the move is there no matter what the
+		// strategy does; this also fills the TurnData caches before the
+		// strategy shares them.
+		dir, _ := firstSafeDir(data)
+		fallback <- dir
+		dir, diagnostics, err := strategy.Move(data)
+		result <- decision{dir, diagnostics, err}
+	}()
+	lastResort := func() Dir {
+		select {
+		case dir := <-fallback:
+			return dir
+		default:
+			return survivalMove(data.req)
+		}
+	}
+
+	select {
+	case d := <-result:
+		if d.err != nil || d.dir < 0 || d.dir >= num_dirs {
+			return lastResort(), d.diagnostics, d.err
+		}
+		return d.dir, d.diagnostics, nil
+	case <-timeout:
+		if dir, ok := data.suggested(); ok {
+			return dir, Diagnostics{"timeout": true, "suggested": true}, nil
+		}
+		return lastResort(), Diagnostics{"timeout": true}, nil
+	}
+}
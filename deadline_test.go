@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	assert "gopkg.in/go-playground/assert.v1"
+)
+
+func TestDecide(t *testing.T) {
+	// Only moving left is safe.
+	req := &MoveRequest{
+		Width:  5,
+		Height: 5,
+		You:    "me",
+		Snakes: []Snake{{ID: "me", Coords: []Point{{4, 0}, {4, 1}, {4, 2}}}},
+	}
+
+	stuck := StrategyFunc(func(data *TurnData) (Dir, Diagnostics, error) {
+		for !data.timeUp() {
+			time.Sleep(time.Millisecond)
+		}
+		time.Sleep(time.Second)
+		return DOWN, nil, nil
+	})
+	start := time.Now()
+	dir, diagnostics, err := decide(stuck, NewTurnData(req, start.Add(20*time.Millisecond)))
+	assert.Equal(t, time.Since(start) < 500*time.Millisecond, true)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, dir, LEFT)
+	assert.Equal(t, diagnostics["timeout"], true)
+
+	thinking := StrategyFunc(func(data *TurnData) (Dir, Diagnostics, error) {
+		data.Suggest(DOWN)
+		time.Sleep(time.Second)
+		return UP, nil, nil
+	})
+	dir, _, _ = decide(thinking, NewTurnData(req, time.Now().Add(20*time.Millisecond)))
+	assert.Equal(t, dir, DOWN)
+
+	failing := StrategyFunc(func(data *TurnData) (Dir, Diagnostics, error) {
+		return UP, nil, errors.New("no idea")
+	})
+	dir, _, err = decide(failing, NewTurnData(req, time.Now().Add(time.Second)))
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, dir, LEFT)
+
+	panicking := StrategyFunc(func(data *TurnData) (Dir, Diagnostics, error) {
+		panic("oops")
+	})
+	dir, _, err = decide(panicking, NewTurnData(req, time.Now().Add(time.Second)))
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, dir, LEFT)
+
+	// Already late: whether or not the fallback got done, the answer is
+	// still safe and on time.
+	start = time.Now()
+	dir, diagnostics, _ = decide(stuck, NewTurnData(req, start.Add(-time.Millisecond)))
+	assert.Equal(t, time.Since(start) < 100*time.Millisecond, true)
+	assert.Equal(t, dir, LEFT)
+	assert.Equal(t, diagnostics["timeout"], true)
+}
+
+func TestMoveBudget(t *testing.T) {
+	assert.Equal(t, moveBudget(&MoveRequest{}), DEFAULT_MOVE_BUDGET)
+	assert.Equal(t, moveBudget(&MoveRequest{Timeout: 500}), 400*time.Millisecond)
+	assert.Equal(t, engineTimeout(&MoveRequest{}), ENGINE_2017_TIMEOUT)
+	assert.Equal(t, engineTimeout(&MoveRequest{Timeout: 500}), 500*time.Millisecond)
+
+	// A configured deadline can't give us more than the engine does.
+	defer func(saved Config) { config = saved }(config)
+	config.moveDeadline = 300 * time.Millisecond
+	assert.Equal(t, moveBudget(&MoveRequest{}), 300*time.Millisecond)
+	assert.Equal(t, moveBudget(&MoveRequest{Timeout: 500}), 300*time.Millisecond)
+	assert.Equal(t, moveBudget(&MoveRequest{Timeout: 250}), 150*time.Millisecond)
+}
+
+// TEST_NODES is the budget tests give minimax and MCTS, so they play the
+// same moves however busy the machine is. TEST_BACKSTOP only stops a
+// search that would otherwise never end.
+const (
+	TEST_NODES    = 1000
+	TEST_BACKSTOP = 10 * time.Second
+)
+
+// testTurn is req's TurnData for a search on a TEST_NODES budget.
+func testTurn(req *MoveRequest) *TurnData {
+	data := NewTurnData(req, time.Now().Add(TEST_BACKSTOP))
+	data.nodes = TEST_NODES
+	return data
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// A Player is one snake in a local game.
+type Player interface {
+	Name() string
+	Move(req *MoveRequest) (Dir, error)
+}
+
+// strategyPlayer plays a registered strategy in-process.
+type strategyPlayer struct {
+	name     string
+	strategy Strategy
+	session  *Session
+	params   Params
+	nodes    int
+}
+
+func NewStrategyPlayer(name string) (Player, error) {
+	return NewTunedPlayer(name, config.Params, 0)
+}
+
+// NewTunedPlayer plays a registered strategy with its own parameters. With
+// nodes above 0 its searches get that many nodes or iterations a move
+// instead of a deadline, so its games replay exactly.
+func NewTunedPlayer(name string, p Params, nodes int) (Player, error) {
+	strategy, ok := lookupStrategy(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown strategy %q", name)
+	}
+	return &strategyPlayer{name: name, strategy: strategy, session: &Session{}, params: p, nodes: nodes}, nil
+}
+
+func (p *strategyPlayer) Name() string { return p.name }
+
+func (p *strategyPlayer) Move(req *MoveRequest) (Dir, error) {
+	var deadline time.Time
+	if p.nodes == 0 {
+		deadline = time.Now().Add(moveBudget(req))
+	}
+	data := NewTurnData(req, deadline)
+	data.history = p.session.History()
+	data.params = p.params
+	data.nodes = p.nodes
+	// Like the server, answer with decide's fallback when the strategy fails.
+	dir, _, _ := decide(p.strategy, data)
+	p.session.Record(data, dir)
+	return dir, nil
+}
+
+type GameConfig struct {
+	Width      int
+	Height     int
+	Seed       int64
+	MaxTurns   int // 0 plays until at most one snake is left
+	MinFood    int
+	FoodChance int // percent chance of spawning extra food each turn
+	Timeout    int // milliseconds in-process players are told they have, 0 for the default budget
+}
+
+var DefaultGameConfig = GameConfig{Width: 11, Height: 11, MinFood: 1, FoodChance: 15}
+
+type TurnLog struct {
+	Turn       int               `json:"turn"`
+	Moves      map[string]string `json:"moves,omitempty"`
+	Eliminated []Elimination     `json:"eliminated,omitempty"`
+	Food       []Point           `json:"food"`
+	Snakes     []Snake           `json:"snakes"`
+}
+
+type GameResult struct {
+	Winner     string        `json:"winner,omitempty"` // "" for a draw
+	Turns      int           `json:"turns"`
+	Eliminated []Elimination `json:"eliminated"`
+}
+
+// Game referees a local match between players.
+type Game struct {
+	Config  GameConfig
+	State   *MoveRequest
+	Players map[string]Player
+	Log     []TurnLog
+	Result  GameResult
+
+	rng   *rand.Rand
+	final map[string]Snake
+	solo  bool
+}
+
+func NewGame(gameConfig GameConfig, players []Player) *Game {
+	g := &Game{
+		Config:  gameConfig,
+		Players: map[string]Player{},
+		rng:     rand.New(rand.NewSource(gameConfig.Seed)),
+		final:   map[string]Snake{},
+		solo:    len(players) == 1,
+		State: &MoveRequest{
+			GameId:  fmt.Sprintf("local-%d", gameConfig.Seed),
+			Width:   gameConfig.Width,
+			Height:  gameConfig.Height,
+			Timeout: gameConfig.Timeout,
+		},
+	}
+
+	starts := g.spawnPoints(len(players))
+	for i, player := range players {
+		id := fmt.Sprintf("snake-%d", i)
+		g.Players[id] = player
+		g.State.Snakes = append(g.State.Snakes, Snake{
+			ID:           id,
+			Name:         player.Name(),
+			HealthPoints: MAX_HEALTH,
+			Coords:       []Point{starts[i], starts[i], starts[i]},
+		})
+	}
+	for range players {
+		g.spawnFood()
+	}
+	g.logTurn(nil, nil)
+	return g
+}
+
+// spawnPoints spreads snakes over the corners and edge midpoints, then
+// falls back to random empty cells.
+func (g *Game) spawnPoints(n int) []Point {
+	w, h := g.Config.Width, g.Config.Height
+	fixed := []Point{
+		{1, 1}, {w - 2, h - 2}, {w - 2, 1}, {1, h - 2},
+		{w / 2, 1}, {w / 2, h - 2}, {1, h / 2}, {w - 2, h / 2},
+	}
+	points := []Point{}
+	used := map[Point]bool{}
+	for _, p := range fixed {
+		if len(points) < n && p.X >= 0 && p.Y >= 0 && p.X < w && p.Y < h && !used[p] {
+			points = append(points, p)
+			used[p] = true
+		}
+	}
+	for len(points) < n {
+		p := Point{X: g.rng.Intn(w), Y: g.rng.Intn(h)}
+		if !used[p] {
+			points = append(points, p)
+			used[p] = true
+		}
+	}
+	return points
+}
+
+// spawnFood drops food on a random empty cell, if there is one.
+func (g *Game) spawnFood() bool {
+	occupied := map[Point]bool{}
+	for _, f := range g.State.Food {
+		occupied[f] = true
+	}
+	for _, snake := range g.State.Snakes {
+		for _, p := range snake.Coords {
+			occupied[p] = true
+		}
+	}
+	empty := []Point{}
+	for x := 0; x < g.Config.Width; x++ {
+		for y := 0; y < g.Config.Height; y++ {
+			if p := (Point{X: x, Y: y}); !occupied[p] {
+				empty = append(empty, p)
+			}
+		}
+	}
+	if len(empty) == 0 {
+		return false
+	}
+	g.State.Food = append(g.State.Food, empty[g.rng.Intn(len(empty))])
+	return true
+}
+
+func (g *Game) Over() bool {
+	if g.Config.MaxTurns > 0 && g.State.Turn >= g.Config.MaxTurns {
+		return true
+	}
+	if g.solo {
+		return len(g.State.Snakes) == 0
+	}
+	return len(g.State.Snakes) <= 1
+}
+
+// requestFor builds the move request a player sees.
+func (g *Game) requestFor(id string) *MoveRequest {
+	req := copyRequest(g.State)
+	req.You = id
+	if snake, ok := g.final[id]; ok {
+		req.Me = &snake
+	}
+	return req
+}
+
+// notify tells every GameWatcher player about the start or end of the game.
+func (g *Game) notify(end bool) {
+	var wg sync.WaitGroup
+	for id, player := range g.Players {
+		watcher, ok := player.(GameWatcher)
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func(id string, watcher GameWatcher) {
+			defer wg.Done()
+			var err error
+			if end {
+				err = watcher.End(g.requestFor(id))
+			} else {
+				err = watcher.Start(g.requestFor(id))
+			}
+			if err != nil {
+				log.Printf("%s: %v\n", g.Players[id].Name(), err)
+			}
+		}(id, watcher)
+	}
+	wg.Wait()
+}
+
+func (g *Game) collectMoves() map[string]Dir {
+	moves := map[string]Dir{}
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	for _, snake := range g.State.Snakes {
+		wg.Add(1)
+		go func(snake Snake) {
+			defer wg.Done()
+			dir, err := playerMove(g.Players[snake.ID], g.requestFor(snake.ID))
+			lock.Lock()
+			defer lock.Unlock()
+			if err != nil || dir < 0 || dir >= num_dirs {
+				// Like the real engine, keep going the same way.
+				dir = defaultMove(snake)
+			}
+			moves[snake.ID] = dir
+		}(snake)
+	}
+	wg.Wait()
+	return moves
+}
+
+// playerMove asks a player for its move, turning a panic into an error so
+// one broken strategy can't take down the whole referee.
+func playerMove(player Player, req *MoveRequest) (dir Dir, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%s panicked: %v", player.Name(), r)
+		}
+	}()
+	return player.Move(req)
+}
+
+// Step plays a single turn.
+func (g *Game) Step() {
+	moves := g.collectMoves()
+	g.State.Turn++
+	moveSnakes(g.State, moves)
+	// Keep every snake as it made its last move, which for the ones about
+	// to go is how /end shows them.
+	for _, snake := range copyRequest(g.State).Snakes {
+		g.final[snake.ID] = snake
+	}
+	eliminated := removeEliminated(g.State)
+	g.Result.Eliminated = append(g.Result.Eliminated, eliminated...)
+
+	if len(g.State.Food) < g.Config.MinFood {
+		for len(g.State.Food) < g.Config.MinFood && g.spawnFood() {
+		}
+	} else if g.rng.Intn(100) < g.Config.FoodChance {
+		g.spawnFood()
+	}
+	g.logTurn(moves, eliminated)
+}
+
+func (g *Game) logTurn(moves map[string]Dir, eliminated []Elimination) {
+	entry := TurnLog{
+		Turn:       g.State.Turn,
+		Eliminated: eliminated,
+		Food:       append([]Point(nil), g.State.Food...),
+		Snakes:     copyRequest(g.State).Snakes,
+	}
+	if moves != nil {
+		entry.Moves = map[string]string{}
+		for id, dir := range moves {
+			entry.Moves[id] = directions[dir]
+		}
+	}
+	g.Log = append(g.Log, entry)
+}
+
+// Run plays the game to the end.
+func (g *Game) Run() GameResult {
+	if g.State.Turn == 0 {
+		g.notify(false)
+	}
+	for !g.Over() {
+		g.Step()
+	}
+	g.Result.Turns = g.State.Turn
+	if len(g.State.Snakes) == 1 {
+		g.Result.Winner = g.State.Snakes[0].ID
+	}
+	g.notify(true)
+	return g.Result
+}
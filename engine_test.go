@@ -0,0 +1,87 @@
+package main
+
+import (
+	"testing"
+
+	assert "gopkg.in/go-playground/assert.v1"
+)
+
+func TestNewGame(t *testing.T) {
+	gameConfig := DefaultGameConfig
+	gameConfig.Width, gameConfig.Height = 7, 7
+	gameConfig.Seed = 1
+	g := NewGame(gameConfig, []Player{fixedPlayer(UP), fixedPlayer(LEFT)})
+
+	assert.Equal(t, g.State.GameId, "local-1")
+	assert.Equal(t, len(g.State.Snakes), 2)
+	assert.Equal(t, g.State.Snakes[0].Coords, []Point{{1, 1}, {1, 1}, {1, 1}})
+	assert.Equal(t, g.State.Snakes[1].Coords, []Point{{5, 5}, {5, 5}, {5, 5}})
+	assert.Equal(t, g.State.Snakes[1].HealthPoints, MAX_HEALTH)
+	// A piece of food per snake, never under one.
+	assert.Equal(t, len(g.State.Food), 2)
+	for _, f := range g.State.Food {
+		assert.NotEqual(t, f, Point{1, 1})
+		assert.NotEqual(t, f, Point{5, 5})
+	}
+	assert.Equal(t, len(g.Log), 1)
+	assert.Equal(t, g.Log[0].Turn, 0)
+	assert.Equal(t, g.Log[0].Food, g.State.Food)
+
+	// The same seed spawns the same food.
+	again := NewGame(gameConfig, []Player{fixedPlayer(UP), fixedPlayer(LEFT)})
+	assert.Equal(t, again.State.Food, g.State.Food)
+}
+
+func TestGameRun(t *testing.T) {
+	gameConfig := DefaultGameConfig
+	gameConfig.Width, gameConfig.Height = 7, 7
+	gameConfig.Seed = 1
+	gameConfig.FoodChance = 0
+	g := NewGame(gameConfig, []Player{fixedPlayer(UP), fixedPlayer(LEFT)})
+	food := append([]Point(nil), g.State.Food...)
+	result := g.Run()
+
+	// snake-0 runs into the top wall on its second move.
+	assert.Equal(t, result, GameResult{
+		Winner:     "snake-1",
+		Turns:      2,
+		Eliminated: []Elimination{{Snake: "snake-0", Cause: CAUSE_WALL, Turn: 2}},
+	})
+	assert.Equal(t, len(g.Log), 3)
+	assert.Equal(t, g.Log[1].Turn, 1)
+	assert.Equal(t, g.Log[1].Moves, map[string]string{"snake-0": "up", "snake-1": "left"})
+	assert.Equal(t, len(g.Log[1].Eliminated), 0)
+	assert.Equal(t, g.Log[1].Snakes[0].Coords, []Point{{1, 0}, {1, 1}, {1, 1}})
+	assert.Equal(t, g.Log[2].Eliminated, result.Eliminated)
+	assert.Equal(t, len(g.Log[2].Snakes), 1)
+	assert.Equal(t, g.Log[2].Food, food)
+
+	assert.Equal(t, g.State.Turn, 2)
+	assert.Equal(t, g.State.Snakes[0].Coords, []Point{{3, 5}, {4, 5}, {5, 5}})
+	assert.Equal(t, g.State.Snakes[0].HealthPoints, MAX_HEALTH-2)
+	assert.Equal(t, g.Over(), true)
+}
+
+func TestGameOver(t *testing.T) {
+	gameConfig := DefaultGameConfig
+	gameConfig.Width, gameConfig.Height = 7, 7
+	gameConfig.MaxTurns = 3
+	g := NewGame(gameConfig, []Player{fixedPlayer(RIGHT), fixedPlayer(LEFT)})
+	result := g.Run()
+	assert.Equal(t, result.Turns, 3)
+	assert.Equal(t, result.Winner, "")
+	assert.Equal(t, len(g.State.Snakes), 2)
+
+	// On its own a snake plays until it is out.
+	gameConfig.MaxTurns = 0
+	g = NewGame(gameConfig, []Player{fixedPlayer(RIGHT)})
+	result = g.Run()
+	assert.Equal(t, result.Turns, 6)
+	assert.Equal(t, len(g.State.Snakes), 0)
+}
+
+// fixedPlayer always makes the same move.
+type fixedPlayer Dir
+
+func (p fixedPlayer) Name() string                       { return directions[p] }
+func (p fixedPlayer) Move(req *MoveRequest) (Dir, error) { return Dir(p), nil }
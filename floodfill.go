@@ -0,0 +1,76 @@
+package main
+
+// reachable counts the cells we could get to if our head were on start
+// after `steps` moves. Snake segments, ours included, count as free once
+// their owner's tail has moved past them by the time we arrive.
+func reachable(data *TurnData, start Point, steps int) int {
+	return reachableAvoiding(data, start, steps, nil)
+}
+
+// reachableAvoiding is reachable with the cells in avoid taken, such as
+// the ones our own body will be lying on.
+func reachableAvoiding(data *TurnData, start Point, steps int, avoid map[Point]bool) int {
+	if !freeAt(data, start, steps) {
+		return 0
+	}
+	seen := map[Point]bool{start: true}
+	frontier := []Point{start}
+	for turn := steps + 1; len(frontier) > 0 && !data.timeUp(); turn++ {
+		next := []Point{}
+		for _, p := range frontier {
+			for dir := UP; dir < num_dirs; dir++ {
+				n := p.Step(dir)
+				if !seen[n] && !avoid[n] && freeAt(data, n, turn) {
+					seen[n] = true
+					next = append(next, n)
+				}
+			}
+		}
+		frontier = next
+	}
+	return len(seen)
+}
+
+// freeAt reports whether p is on the board and empty `turn` moves from now,
+// counting on every snake's tail to keep moving unless it has just eaten.
+func freeAt(data *TurnData, p Point, turn int) bool {
+	return data.board.Contains(p) && turn >= data.board.Vacates(p)
+}
+
+// moveSpace is the room we'd have after moving in dir, 0 if we can't.
+func moveSpace(data *TurnData, dir Dir) int {
+	if data.space == nil {
+		data.space = make([]int, num_dirs)
+		for d := UP; d < num_dirs; d++ {
+			data.space[d] = reachable(data, data.mysnake.Coords[0].Step(d), 1)
+		}
+	}
+	return data.space[dir]
+}
+
+// cramped reports whether moving in dir leaves us less room than our own
+// length while another move would give us more.
+func cramped(data *TurnData, dir Dir) bool {
+	space := moveSpace(data, dir)
+	if space >= len(data.mysnake.Coords) {
+		return false
+	}
+	for d := UP; d < num_dirs; d++ {
+		if moveSpace(data, d) > space {
+			return true
+		}
+	}
+	return false
+}
+
+// roomiestDir is the move that leaves us the most room among the safest
+// ones.
+func roomiestDir(data *TurnData) Dir {
+	best, safety := firstSafeDir(data)
+	for dir := UP; dir < num_dirs; dir++ {
+		if safeMove(data, dir) == safety && moveSpace(data, dir) > moveSpace(data, best) {
+			best = dir
+		}
+	}
+	return best
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	assert "gopkg.in/go-playground/assert.v1"
+)
+
+func TestReachable(t *testing.T) {
+	// A long snake walls off rows 3 and 4. Our own body inside the pocket
+	// clears out in time, the wall doesn't.
+	req := &MoveRequest{
+		Width:  5,
+		Height: 5,
+		You:    "me",
+		Snakes: []Snake{
+			{ID: "me", Coords: []Point{{2, 3}, {2, 4}, {3, 4}}},
+			{ID: "wall", Coords: []Point{
+				{4, 2}, {3, 2}, {2, 2}, {1, 2}, {0, 2}, {0, 1},
+				{1, 1}, {2, 1}, {3, 1}, {4, 1}, {4, 0},
+			}},
+		},
+	}
+	data := NewTurnData(req, time.Time{})
+
+	assert.Equal(t, reachable(data, Point{1, 3}, 1), 10)
+	assert.Equal(t, reachable(data, Point{-1, 3}, 1), 0)
+	assert.Equal(t, reachable(data, Point{2, 2}, 1), 0)
+	assert.Equal(t, reachable(data, Point{2, 4}, 1), 0)
+}
+
+func TestCramped(t *testing.T) {
+	// Going left leads into a one-cell dead end, right is open.
+	req := &MoveRequest{
+		Width:  5,
+		Height: 5,
+		You:    "me",
+		Snakes: []Snake{
+			{ID: "me", Coords: []Point{{1, 4}, {1, 3}, {1, 2}, {1, 1}}},
+			{ID: "wall", Coords: []Point{{0, 0}, {0, 1}, {0, 2}, {0, 3}, {2, 0}, {2, 1}}},
+		},
+	}
+	data := NewTurnData(req, time.Time{})
+
+	assert.Equal(t, moveSpace(data, LEFT), 1)
+	assert.Equal(t, cramped(data, LEFT), true)
+	assert.Equal(t, cramped(data, RIGHT), false)
+	assert.Equal(t, safeMove(data, LEFT), 0)
+	assert.Equal(t, findFood(data), RIGHT)
+}
+
+func TestSafeMoveTails(t *testing.T) {
+	// Boxed in a corner, the only way out is where our tail is now.
+	req, err := ParseBoard(`
+		Aa.....
+		0a.....
+		.......
+		A: me health=90
+	`)
+	assert.Equal(t, err, nil)
+	data := NewTurnData(req, time.Time{})
+	assert.Equal(t, safeMove(data, DOWN), 2)
+	dir, _ := firstSafeDir(data)
+	assert.Equal(t, dir, DOWN)
+
+	// Not right after eating, though.
+	req.Snakes[0].HealthPoints = MAX_HEALTH
+	assert.Equal(t, safeMove(NewTurnData(req, time.Time{}), DOWN), 0)
+
+	// An enemy tail is only a gamble when its head is next to food.
+	req, err = ParseBoard(`
+		.A1b...
+		.a.B...
+		.0.....
+		A: me health=90
+		B: them health=90
+	`)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, safeMove(NewTurnData(req, time.Time{}), RIGHT), 2)
+	req.Food = []Point{{4, 1}}
+	assert.Equal(t, safeMove(NewTurnData(req, time.Time{}), RIGHT), 1)
+}
+
+func TestBFS(t *testing.T) {
+	// An open board used to have bfs walking every simple path.
+	req := &MoveRequest{
+		Width:  19,
+		Height: 19,
+		You:    "me",
+		Food:   []Point{{18, 18}},
+		Snakes: []Snake{
+			{ID: "me", HealthPoints: 90, Coords: []Point{{9, 9}, {9, 8}, {9, 7}}},
+			{ID: "them", HealthPoints: 90, Coords: []Point{{2, 2}, {2, 3}, {2, 4}}},
+		},
+	}
+	data := NewTurnData(req, time.Time{})
+	dir := bfs(data, false)
+	assert.Equal(t, dir == DOWN || dir == RIGHT, true)
+	dir = bfs(data, true)
+	assert.Equal(t, dir == LEFT || dir == DOWN, true)
+}
@@ -1,23 +1,64 @@
 package main
 
 import (
+	"flag"
 	"log"
 	"net/http"
 	"os"
+	"strings"
+	"time"
 )
 
 func main() {
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "JSON configuration file; environment variables override it")
+	flag.Parse()
+
+	// The commands play with the built-in defaults; only the server, and
+	// replay, which decides as the server would, need its configuration.
+	var err error
+	if args := flag.Args(); len(args) > 0 {
+		switch args[0] {
+		case "simulate":
+			err = runSimulate(args[1:])
+		case "replay":
+			if config, err = LoadConfig(*configPath); err == nil {
+				err = runReplay(args[1:])
+			}
+		case "tournament":
+			err = runTournament(args[1:])
+		case "tune":
+			err = runTune(args[1:])
+		default:
+			log.Fatalf("Unknown command %q\n", args[0])
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if config, err = LoadConfig(*configPath); err != nil {
+		log.Fatal(err)
+	}
+
 	fs := http.FileServer(http.Dir("static"))
-	http.Handle("/", fs)
+	http.HandleFunc("/", handleIndex(fs))
 
 	http.HandleFunc("/start", handleStart)
-	http.HandleFunc("/move", handleMove)
+	http.HandleFunc("/move", recoverMove(handleMove))
+	http.HandleFunc("/end", handleEnd)
+
+	go sessions.Janitor(time.Minute)
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "9000"
+	if config.RecordDir != "" {
+		if recorder, err = NewRecorder(config.RecordDir); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Recording games to %s\n", config.RecordDir)
 	}
 
-	log.Printf("Running server on port %s...\n", port)
-	http.ListenAndServe(":"+port, nil)
+	log.Printf("Strategies: %s\n", strings.Join(strategyNames(), ", "))
+	log.Printf("Playing %q with %s, %+v\n", config.Name, config.Strategy, config.Params)
+	log.Printf("Running server on port %s...\n", config.Port)
+	http.ListenAndServe(":"+config.Port, nil)
 }
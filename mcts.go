@@ -0,0 +1,210 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+)
+
+const (
+	MCTS_EXPLORATION   = 1.4
+	MCTS_ROLLOUT_DEPTH = 20
+)
+
+// mctsNode is one position in the search tree. Moves are simultaneous, so
+// selection is decoupled: every snake keeps its own statistics for its own
+// moves and picks with UCB1, and the joint move leads to a child.
+type mctsNode struct {
+	state    *MoveRequest
+	snakes   []string
+	moves    [][]Dir
+	visits   [][]int
+	rewards  [][]float64
+	n        int
+	children map[int]*mctsNode
+	terminal bool
+}
+
+func newMCTSNode(state *MoveRequest, me string) *mctsNode {
+	node := &mctsNode{state: state, children: map[int]*mctsNode{}}
+	for _, snake := range state.Snakes {
+		moves := legalMoves(state, snake.ID)
+		node.snakes = append(node.snakes, snake.ID)
+		node.moves = append(node.moves, moves)
+		node.visits = append(node.visits, make([]int, len(moves)))
+		node.rewards = append(node.rewards, make([]float64, len(moves)))
+	}
+	node.terminal = len(state.Snakes) <= 1 || !alive(state, me)
+	return node
+}
+
+// legalMoves are the moves that aren't moveBlocked. A trapped snake gets
+// all of candidates.
+func legalMoves(state *MoveRequest, id string) []Dir {
+	all := candidates(state, id)
+	moves := make([]Dir, 0, len(all))
+	for _, dir := range all {
+		if !moveBlocked(state, id, dir) {
+			moves = append(moves, dir)
+		}
+	}
+	if len(moves) == 0 {
+		return all
+	}
+	return moves
+}
+
+// choose picks a move index for every snake with UCB1, trying each move
+// once first.
+func (node *mctsNode) choose(rng *rand.Rand) []int {
+	choice := make([]int, len(node.snakes))
+	for i := range node.snakes {
+		unvisited := []int{}
+		for m, v := range node.visits[i] {
+			if v == 0 {
+				unvisited = append(unvisited, m)
+			}
+		}
+		if len(unvisited) > 0 {
+			choice[i] = unvisited[rng.Intn(len(unvisited))]
+			continue
+		}
+		best := math.Inf(-1)
+		for m, v := range node.visits[i] {
+			ucb := node.rewards[i][m]/float64(v) + MCTS_EXPLORATION*math.Sqrt(math.Log(float64(node.n))/float64(v))
+			if ucb > best {
+				best, choice[i] = ucb, m
+			}
+		}
+	}
+	return choice
+}
+
+func (node *mctsNode) jointMoves(choice []int) (int, map[string]Dir) {
+	key := 0
+	moves := map[string]Dir{}
+	for i, id := range node.snakes {
+		dir := node.moves[i][choice[i]]
+		key = key*int(num_dirs) + int(dir)
+		moves[id] = dir
+	}
+	return key, moves
+}
+
+// playout moves every snake at random among its legal moves.
+func playout(state *MoveRequest, me string, rng *rand.Rand) {
+	for depth := 0; depth < MCTS_ROLLOUT_DEPTH && len(state.Snakes) > 1 && alive(state, me); depth++ {
+		moves := map[string]Dir{}
+		for _, snake := range state.Snakes {
+			legal := legalMoves(state, snake.ID)
+			moves[snake.ID] = legal[rng.Intn(len(legal))]
+		}
+		advance(state, moves)
+	}
+}
+
+// mctsReward scores the end of a playout for every snake in [0, 1]: 0 when
+// dead, 1 when the last one standing, otherwise more the longer it is.
+func mctsReward(state *MoveRequest, ids []string) map[string]float64 {
+	rewards := map[string]float64{}
+	longest := 0
+	for _, snake := range state.Snakes {
+		if len(snake.Coords) > longest {
+			longest = len(snake.Coords)
+		}
+	}
+	for _, id := range ids {
+		snake := getSnake(state, id)
+		switch {
+		case len(snake.Coords) == 0:
+			rewards[id] = 0
+		case len(state.Snakes) == 1:
+			rewards[id] = 1
+		default:
+			rewards[id] = 0.5 + 0.5*float64(len(snake.Coords))/float64(longest)
+		}
+	}
+	return rewards
+}
+
+// mctsMove runs decoupled UCT from the current position until the
+// deadline or iteration budget and plays our most visited move.
+func mctsMove(data *TurnData) (Dir, Diagnostics, error) {
+	me := data.mysnake.ID
+	if len(data.mysnake.Coords) == 0 {
+		dir, _ := firstSafeDir(data)
+		return dir, nil, nil
+	}
+	rng := rand.New(rand.NewSource(int64(data.req.Turn)))
+	root := newMCTSNode(copyRequest(data.req), me)
+	ids := root.snakes
+
+	mine := 0
+	for i, id := range root.snakes {
+		if id == me {
+			mine = i
+		}
+	}
+
+	iterations := 0
+	for !data.searchDone(iterations) && !root.terminal {
+		path := []*mctsNode{root}
+		choices := [][]int{}
+		node := root
+		for !node.terminal {
+			choice := node.choose(rng)
+			key, moves := node.jointMoves(choice)
+			choices = append(choices, choice)
+			child, ok := node.children[key]
+			if !ok {
+				next := copyRequest(node.state)
+				advance(next, moves)
+				child = newMCTSNode(next, me)
+				node.children[key] = child
+				path = append(path, child)
+				break
+			}
+			path = append(path, child)
+			node = child
+		}
+
+		final := copyRequest(path[len(path)-1].state)
+		playout(final, me, rng)
+		rewards := mctsReward(final, ids)
+
+		for depth, node := range path {
+			node.n++
+			if depth < len(choices) {
+				for i, id := range node.snakes {
+					node.visits[i][choices[depth][i]]++
+					node.rewards[i][choices[depth][i]] += rewards[id]
+				}
+			}
+		}
+
+		iterations++
+		if iterations%100 == 0 {
+			data.Suggest(root.best(mine))
+		}
+	}
+
+	if iterations == 0 {
+		dir, _ := firstSafeDir(data)
+		return dir, Diagnostics{"iterations": 0}, nil
+	}
+	return root.best(mine), Diagnostics{"iterations": iterations}, nil
+}
+
+// best is the most visited move of snake i.
+func (node *mctsNode) best(i int) Dir {
+	most := 0
+	for m, v := range node.visits[i] {
+		if v > node.visits[i][most] {
+			most = m
+		}
+	}
+	return node.moves[i][most]
+}
+
+func init() {
+	RegisterStrategy("mcts", StrategyFunc(mctsMove))
+}
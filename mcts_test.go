@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+
+	assert "gopkg.in/go-playground/assert.v1"
+)
+
+func TestLegalMoves(t *testing.T) {
+	req := &MoveRequest{
+		Width:  5,
+		Height: 5,
+		Snakes: []Snake{
+			{ID: "me", Coords: []Point{{0, 0}, {1, 0}, {2, 0}}},
+			{ID: "them", Coords: []Point{{0, 2}, {0, 1}, {1, 1}, {1, 2}}},
+		},
+	}
+	// Down is their neck, left and up are walls, right is our own neck.
+	assert.Equal(t, legalMoves(req, "me"), []Dir{DOWN})
+
+	// Their tail at (1, 2) moves out of the way in time.
+	assert.Equal(t, legalMoves(req, "them"), []Dir{RIGHT, DOWN})
+}
+
+func TestMCTSAvoidsTraps(t *testing.T) {
+	// Three snakes; moving up walks into a dead end that stays shut.
+	req := &MoveRequest{
+		Width:  7,
+		Height: 7,
+		You:    "me",
+		Snakes: []Snake{
+			{ID: "me", HealthPoints: 90, Coords: []Point{{0, 3}, {1, 3}, {2, 3}, {3, 3}}},
+			{ID: "a", HealthPoints: 90, Coords: []Point{
+				{4, 0}, {3, 0}, {2, 0}, {1, 0}, {0, 0}, {0, 1},
+				{1, 1}, {1, 2}, {2, 2}, {3, 2}, {4, 2},
+			}},
+			{ID: "b", HealthPoints: 90, Coords: []Point{{6, 6}, {6, 5}, {6, 4}}},
+		},
+	}
+	dir, diagnostics, err := mctsMove(testTurn(req))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, diagnostics["iterations"].(int) > 0, true)
+	assert.Equal(t, dir, DOWN)
+}
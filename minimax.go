@@ -0,0 +1,177 @@
+package main
+
+import (
+	"errors"
+	"math"
+)
+
+// Scores for the end of a 1v1 game. Wins and losses are adjusted by the
+// remaining depth so sooner wins and later losses are preferred.
+const (
+	WIN  = 1000000
+	LOSS = -WIN
+	DRAW = LOSS / 2
+
+	MAX_MINIMAX_DEPTH = 20
+)
+
+var errTimeUp = errors.New("out of time")
+
+// minimax searches a 1v1 game with alpha-beta pruning. Both snakes move at
+// once, so each ply pairs our move (maximized) with the opponent's reply
+// (minimized) and plays them together with advance.
+type minimax struct {
+	data  *TurnData
+	me    string
+	them  string
+	nodes int
+}
+
+// candidates are the moves worth searching for a snake: everything but
+// straight into a wall or back into its own neck.
+func candidates(state *MoveRequest, id string) []Dir {
+	snake := getSnake(state, id)
+	dirs := make([]Dir, 0, num_dirs)
+	for dir := UP; dir < num_dirs; dir++ {
+		p := snake.Coords[0].Step(dir)
+		if !inBounds(state, p) {
+			continue
+		}
+		if len(snake.Coords) > 1 && p == snake.Coords[1] {
+			continue
+		}
+		dirs = append(dirs, dir)
+	}
+	if len(dirs) == 0 {
+		dirs = append(dirs, UP)
+	}
+	return dirs
+}
+
+// evaluate scores a position for us: survival first, then length, the
+// room each snake has to move and the territory each one controls.
+func (m *minimax) evaluate(state *MoveRequest, depth int) int {
+	mine, theirs := alive(state, m.me), alive(state, m.them)
+	switch {
+	case !mine && !theirs:
+		return DRAW
+	case !mine:
+		return LOSS - depth
+	case !theirs:
+		return WIN + depth
+	}
+
+	me, them := getSnake(state, m.me), getSnake(state, m.them)
+	leaf := NewTurnData(state, m.data.deadline)
+	leaf.params = m.data.params
+	space := func(snake Snake) int {
+		best := 0
+		for dir := UP; dir < num_dirs; dir++ {
+			if room := reachable(leaf, snake.Coords[0].Step(dir), 1); room > best {
+				best = room
+			}
+		}
+		return best
+	}
+	mySpace, theirSpace := space(me), space(them)
+
+	regions := territory(leaf)
+	score := 100*(len(me.Coords)-len(them.Coords)) + 10*(mySpace-theirSpace) +
+		5*(regions.Counts[m.me]-regions.Counts[m.them])
+	if mySpace < len(me.Coords) {
+		score -= 1000
+	}
+	if theirSpace < len(them.Coords) {
+		score += 1000
+	}
+	if me.HealthPoints < 15 {
+		score -= 50 * (15 - me.HealthPoints)
+	}
+	return score
+}
+
+// search returns the value of state with depth plies left, and our best
+// move in it.
+func (m *minimax) search(state *MoveRequest, depth int, alpha int, beta int) (int, Dir, error) {
+	if m.data.searchDone(m.nodes) {
+		return 0, UP, errTimeUp
+	}
+	m.nodes++
+	if depth == 0 || !alive(state, m.me) || !alive(state, m.them) {
+		return m.evaluate(state, depth), UP, nil
+	}
+
+	best, bestDir := math.MinInt32, UP
+	for _, mine := range candidates(state, m.me) {
+		worst := math.MaxInt32
+		for _, theirs := range candidates(state, m.them) {
+			next := copyRequest(state)
+			advance(next, map[string]Dir{m.me: mine, m.them: theirs})
+			value, _, err := m.search(next, depth-1, alpha, min(beta, worst))
+			if err != nil {
+				return 0, UP, err
+			}
+			if value < worst {
+				worst = value
+			}
+			if worst <= alpha {
+				// The opponent already has a reply that makes this move
+				// no better than one we have.
+				break
+			}
+		}
+		if worst > best {
+			best, bestDir = worst, mine
+		}
+		if best > alpha {
+			alpha = best
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+	return best, bestDir, nil
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// minimaxMove deepens the search until the deadline or node budget and
+// plays the best move of the deepest completed search.
+func minimaxMove(data *TurnData) (Dir, Diagnostics, error) {
+	var them string
+	for _, snake := range data.req.Snakes {
+		if snake.ID != data.mysnake.ID {
+			them = snake.ID
+		}
+	}
+	if len(data.req.Snakes) != 2 || them == "" || len(data.mysnake.Coords) == 0 {
+		return UP, nil, errors.New("minimax needs exactly two snakes")
+	}
+
+	m := &minimax{data: data, me: data.mysnake.ID, them: them}
+	dir, _ := firstSafeDir(data)
+	diagnostics := Diagnostics{"depth": 0}
+	for depth := 1; depth <= MAX_MINIMAX_DEPTH; depth++ {
+		value, best, err := m.search(data.req, depth, math.MinInt32, math.MaxInt32)
+		if err != nil {
+			break
+		}
+		dir = best
+		data.Suggest(dir)
+		diagnostics["depth"], diagnostics["score"] = depth, value
+		if value >= WIN || value <= LOSS {
+			break
+		}
+	}
+	diagnostics["nodes"] = m.nodes
+	return dir, diagnostics, nil
+}
+
+func init() {
+	RegisterStrategy("minimax", StrategyFunc(minimaxMove))
+}
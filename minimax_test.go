@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+
+	assert "gopkg.in/go-playground/assert.v1"
+)
+
+func TestMinimaxAvoidsLosingHeadOn(t *testing.T) {
+	// Moving up could meet the longer snake's head at (3, 2).
+	req := &MoveRequest{
+		Width:  7,
+		Height: 7,
+		You:    "me",
+		Snakes: []Snake{
+			{ID: "me", HealthPoints: 90, Coords: []Point{{3, 3}, {3, 4}, {3, 5}}},
+			{ID: "them", HealthPoints: 90, Coords: []Point{{3, 1}, {3, 0}, {2, 0}, {1, 0}, {0, 0}}},
+		},
+	}
+	dir, _, err := minimaxMove(testTurn(req))
+	assert.Equal(t, err, nil)
+	assert.NotEqual(t, dir, UP)
+}
+
+func TestMinimaxFindsForcedWin(t *testing.T) {
+	// Going down either wins head-on or shuts them in the corner.
+	req := &MoveRequest{
+		Width:  7,
+		Height: 7,
+		You:    "me",
+		Snakes: []Snake{
+			{ID: "me", HealthPoints: 90, Coords: []Point{{1, 0}, {2, 0}, {3, 0}, {4, 0}, {5, 0}}},
+			{ID: "them", HealthPoints: 90, Coords: []Point{{0, 1}, {0, 2}, {0, 3}}},
+		},
+	}
+	dir, diagnostics, err := minimaxMove(testTurn(req))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, dir, DOWN)
+	assert.Equal(t, diagnostics["score"].(int) >= WIN, true)
+}
+
+func TestMinimaxNeedsTwoSnakes(t *testing.T) {
+	req := &MoveRequest{
+		Width:  7,
+		Height: 7,
+		You:    "me",
+		Snakes: []Snake{{ID: "me", HealthPoints: 90, Coords: []Point{{1, 0}, {2, 0}}}},
+	}
+	_, _, err := minimaxMove(testTurn(req))
+	assert.NotEqual(t, err, nil)
+}
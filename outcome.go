@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Outcome is the record written for every finished game.
+type Outcome struct {
+	GameId    string    `json:"game_id"`
+	Snake     string    `json:"snake"`
+	Won       bool      `json:"won"`
+	Survived  bool      `json:"survived"`
+	Turns     int       `json:"turns"`
+	Length    int       `json:"length"`
+	Opponents int       `json:"opponents_left"`
+	Cause     string    `json:"cause,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// newOutcome describes the game that ended with req. last is the last
+// move request we answered, nil if we don't know it: a snake that is out
+// lasted until then, and the final board only explains how it went out if
+// that was the turn before the end.
+func newOutcome(req *MoveRequest, last *MoveRequest) Outcome {
+	outcome := Outcome{
+		GameId: req.GameId,
+		Snake:  req.You,
+		Turns:  req.Turn,
+		Time:   time.Now().UTC(),
+	}
+
+	me := req.Me
+	for i, snake := range req.Snakes {
+		if snake.ID == req.You {
+			outcome.Survived = true
+			me = &req.Snakes[i]
+		} else {
+			outcome.Opponents++
+		}
+	}
+	outcome.Won = outcome.Survived && outcome.Opponents == 0
+	if !outcome.Survived && last != nil {
+		outcome.Turns = last.Turn
+	}
+
+	if me != nil {
+		outcome.Length = len(me.Coords)
+		if !outcome.Survived && last != nil && last.Turn+1 == req.Turn {
+			outcome.Cause = causeOfDeath(req, me)
+		}
+	}
+	return outcome
+}
+
+// causeOfDeath puts me back on the final board and asks the rules why it
+// can't be there. It returns "" when the board doesn't explain it.
+func causeOfDeath(req *MoveRequest, me *Snake) string {
+	if len(me.Coords) == 0 {
+		return ""
+	}
+	state := copyRequest(req)
+	state.Snakes = append(state.Snakes, *me)
+	for _, e := range eliminations(state) {
+		if e.Snake == me.ID {
+			return e.Cause
+		}
+	}
+	return ""
+}
+
+var outcomeLock sync.Mutex
+
+// recordOutcome appends the outcome as a JSON line to the configured
+// outcome file, or logs it when there is none.
+func recordOutcome(outcome Outcome) error {
+	line, err := json.Marshal(outcome)
+	if err != nil {
+		return err
+	}
+
+	path := config.OutcomeFile
+	if path == "" {
+		log.Printf("Game over: %s\n", line)
+		return nil
+	}
+
+	outcomeLock.Lock()
+	defer outcomeLock.Unlock()
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
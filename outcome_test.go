@@ -0,0 +1,66 @@
+package main
+
+import (
+	"testing"
+
+	assert "gopkg.in/go-playground/assert.v1"
+)
+
+func TestOutcome(t *testing.T) {
+	me := Snake{ID: "me", HealthPoints: 50, Coords: []Point{{3, 3}, {3, 4}, {3, 5}}}
+	enemy := Snake{ID: "enemy", HealthPoints: 50, Coords: []Point{{5, 5}, {5, 6}, {5, 7}, {5, 8}, {5, 9}}}
+
+	won := newOutcome(&MoveRequest{GameId: "g", Turn: 40, Width: 10, Height: 10, You: "me", Snakes: []Snake{me}}, &MoveRequest{Turn: 39})
+	assert.Equal(t, won.Survived, true)
+	assert.Equal(t, won.Won, true)
+	assert.Equal(t, won.Turns, 40)
+	assert.Equal(t, won.Length, 3)
+	assert.Equal(t, won.Cause, "")
+
+	alive := newOutcome(&MoveRequest{Width: 10, Height: 10, You: "me", Snakes: []Snake{me, enemy}}, nil)
+	assert.Equal(t, alive.Survived, true)
+	assert.Equal(t, alive.Won, false)
+	assert.Equal(t, alive.Opponents, 1)
+
+	causes := []struct {
+		head   Point
+		health int
+		cause  string
+	}{
+		{Point{-1, 3}, 50, CAUSE_WALL},
+		{Point{3, 10}, 50, CAUSE_WALL},
+		{Point{2, 3}, 0, CAUSE_STARVATION},
+		{Point{3, 4}, 50, CAUSE_SELF},
+		{Point{5, 6}, 50, CAUSE_BODY},
+		{Point{5, 5}, 50, CAUSE_HEAD_TO_HEAD},
+		{Point{0, 0}, 50, ""},
+	}
+	for _, c := range causes {
+		dead := Snake{ID: "me", HealthPoints: c.health, Coords: []Point{c.head, {3, 3}, {3, 4}, {3, 5}}}
+		outcome := newOutcome(&MoveRequest{Turn: 40, Width: 10, Height: 10, You: "me", Snakes: []Snake{enemy}, Me: &dead}, &MoveRequest{Turn: 39})
+		assert.Equal(t, outcome.Survived, false)
+		assert.Equal(t, outcome.Turns, 39)
+		assert.Equal(t, outcome.Length, 4)
+		assert.Equal(t, outcome.Cause, c.cause)
+	}
+
+	// Out long before the end, the final board says nothing about why.
+	dead := Snake{ID: "me", HealthPoints: 50, Coords: []Point{{-1, 3}, {0, 3}}}
+	early := newOutcome(&MoveRequest{Turn: 40, Width: 10, Height: 10, You: "me", Snakes: []Snake{enemy}, Me: &dead}, &MoveRequest{Turn: 12})
+	assert.Equal(t, early.Turns, 12)
+	assert.Equal(t, early.Cause, "")
+	unknown := newOutcome(&MoveRequest{Turn: 40, Width: 10, Height: 10, You: "me", Snakes: []Snake{enemy}, Me: &dead}, nil)
+	assert.Equal(t, unknown.Cause, "")
+}
+
+func TestLocalGameOutcome(t *testing.T) {
+	// The engine shows a snake at /end as it made its fatal move.
+	gameConfig := DefaultGameConfig
+	gameConfig.Width, gameConfig.Height = 5, 5
+	g := NewGame(gameConfig, []Player{fixedPlayer(UP), fixedPlayer(LEFT)})
+	g.Run()
+	req := g.requestFor("snake-0")
+	outcome := newOutcome(req, &MoveRequest{Turn: req.Turn - 1})
+	assert.Equal(t, outcome.Survived, false)
+	assert.Equal(t, outcome.Cause, CAUSE_WALL)
+}
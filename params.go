@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+)
+
+// Params are the numbers the heuristic strategies are tuned by.
+type Params struct {
+	// Only attack above this much health.
+	AttackHealth int `json:"attack_health"`
+	// Only attack snakes at least this much shorter than us.
+	AttackMargin int `json:"attack_margin"`
+	// Only attack while holding this much territory per segment of ours.
+	AttackTerritory float64 `json:"attack_territory"`
+	// bfs gives up on targets further than this many times width plus
+	// height.
+	BFSReach float64 `json:"bfs_reach"`
+}
+
+var DefaultParams = Params{
+	AttackHealth:    25,
+	AttackMargin:    1,
+	AttackTerritory: 1,
+	BFSReach:        1,
+}
+
+// paramRange is one field of Params seen as a number within bounds, which
+// is how the tuner sees them.
+type paramRange struct {
+	name     string
+	min, max float64
+	get      func(p Params) float64
+	set      func(p *Params, v float64)
+}
+
+var paramRanges = []paramRange{
+	{"attack_health", 0, MAX_HEALTH,
+		func(p Params) float64 { return float64(p.AttackHealth) },
+		func(p *Params, v float64) { p.AttackHealth = round(v) }},
+	{"attack_margin", 1, 10,
+		func(p Params) float64 { return float64(p.AttackMargin) },
+		func(p *Params, v float64) { p.AttackMargin = round(v) }},
+	{"attack_territory", 0, 5,
+		func(p Params) float64 { return p.AttackTerritory },
+		func(p *Params, v float64) { p.AttackTerritory = v }},
+	{"bfs_reach", 0.25, 2,
+		func(p Params) float64 { return p.BFSReach },
+		func(p *Params, v float64) { p.BFSReach = v }},
+}
+
+func round(v float64) int { return int(math.Floor(v + 0.5)) }
+
+// clamp keeps v within the range.
+func (r paramRange) clamp(v float64) float64 {
+	return math.Max(r.min, math.Min(r.max, v))
+}
+
+// Validate checks every field is within its range.
+func (p Params) Validate() error {
+	for _, r := range paramRanges {
+		if v := r.get(p); v < r.min || v > r.max {
+			return fmt.Errorf("%s is %v, not between %v and %v", r.name, v, r.min, r.max)
+		}
+	}
+	return nil
+}
+
+// LoadParams reads a parameter file as written by tune. Fields the file
+// leaves out keep their default.
+func LoadParams(path string) (Params, error) {
+	p := DefaultParams
+	body, err := ioutil.ReadFile(path)
+	if err != nil {
+		return p, err
+	}
+	if err := json.Unmarshal(body, &p); err != nil {
+		return p, fmt.Errorf("%s: %v", path, err)
+	}
+	if err := p.Validate(); err != nil {
+		return p, fmt.Errorf("%s: %v", path, err)
+	}
+	return p, nil
+}
+
+// Save writes the parameters as JSON.
+func (p Params) Save(path string) error {
+	body, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(body, '\n'), 0644)
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	assert "gopkg.in/go-playground/assert.v1"
+)
+
+func TestParams(t *testing.T) {
+	assert.Equal(t, DefaultParams.Validate(), nil)
+	p := DefaultParams
+	p.AttackMargin = 0
+	assert.NotEqual(t, p.Validate(), nil)
+
+	dir, err := ioutil.TempDir("", "params")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "params.json")
+
+	// Missing fields keep their defaults.
+	assert.Equal(t, ioutil.WriteFile(path, []byte(`{"attack_health": 50}`), 0644), nil)
+	p, err = LoadParams(path)
+	assert.Equal(t, err, nil)
+	want := DefaultParams
+	want.AttackHealth = 50
+	assert.Equal(t, p, want)
+
+	p.BFSReach = 1.5
+	assert.Equal(t, p.Save(path), nil)
+	loaded, err := LoadParams(path)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, loaded, p)
+
+	assert.Equal(t, ioutil.WriteFile(path, []byte(`{"attack_health": 500}`), 0644), nil)
+	_, err = LoadParams(path)
+	assert.NotEqual(t, err, nil)
+}
+
+func TestShouldAttackParams(t *testing.T) {
+	req, err := ParseBoard(`
+		.......
+		.Aaaa0.
+		.......
+		.......
+		.Bb1...
+		.......
+		.......
+		A: me health=50
+		B: them health=90
+	`)
+	assert.Equal(t, err, nil)
+	data := NewTurnData(req, time.Time{})
+	assert.Equal(t, shouldAttack(data), true)
+
+	data.params.AttackHealth = 50
+	assert.Equal(t, shouldAttack(data), false)
+	data.params = DefaultParams
+	data.params.AttackMargin = 3
+	assert.Equal(t, shouldAttack(data), false)
+	data.params = DefaultParams
+	data.params.AttackTerritory = 5
+	assert.Equal(t, shouldAttack(data), false)
+}
+
+func TestTuner(t *testing.T) {
+	assert.Equal(t, gameScore(GameResult{Winner: "snake-1", Turns: 40}, "snake-1"), 1.0)
+	assert.Equal(t, gameScore(GameResult{Turns: 40, Eliminated: []Elimination{{Snake: "snake-1", Turn: 10}}}, "snake-1"), 0.125)
+	assert.Equal(t, gameScore(GameResult{Turns: 40}, "snake-1"), 0.5)
+
+	tn := &tuner{rng: rand.New(rand.NewSource(1))}
+	population := []*candidate{}
+	for i := 0; i < 8; i++ {
+		p := tn.mutate(DefaultParams, 1)
+		assert.Equal(t, p.Validate(), nil)
+		population = append(population, &candidate{params: p})
+	}
+	next := tn.breed(population)
+	assert.Equal(t, len(next), 8)
+	assert.Equal(t, next[0], population[0])
+	assert.Equal(t, next[1], population[1])
+
+	dir, err := ioutil.TempDir("", "tune")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "params.json")
+	args := []string{"-strategy", "bfs", "-population", "3", "-generations", "2", "-games", "2",
+		"-snakes", "2", "-width", "7", "-height", "7", "-max-turns", "30", "-out", path}
+	assert.Equal(t, runTune(args), nil)
+	_, err = LoadParams(path)
+	assert.Equal(t, err, nil)
+}
+
+func TestTuneEvaluate(t *testing.T) {
+	gameConfig := DefaultGameConfig
+	gameConfig.Width, gameConfig.Height = 7, 7
+	gameConfig.Seed = 1
+	gameConfig.MaxTurns = 40
+	tn := &tuner{strategy: DEFAULT_STRATEGY, baseline: DefaultParams, snakes: 3, games: 2, nodes: 100, config: gameConfig}
+
+	// The same games score the same every time.
+	p := Params{AttackHealth: 90, AttackMargin: 5, AttackTerritory: 4}
+	first, err := tn.evaluate(p, 0)
+	assert.Equal(t, err, nil)
+	again, err := tn.evaluate(p, 0)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, again, first)
+}
+
+func TestRunTuneRefuses(t *testing.T) {
+	fewest, ok := usesParams("greedy")
+	assert.Equal(t, ok, true)
+	assert.Equal(t, fewest, 3)
+	_, ok = usesParams("minimax")
+	assert.Equal(t, ok, false)
+
+	for _, args := range [][]string{
+		{"-strategy", "psychic"},
+		{"-strategy", "minimax"},
+		{"-strategy", "food"},
+		{"-strategy", "greedy", "-snakes", "2"},
+		{"-strategy", "bfs", "-population", "1"},
+	} {
+		assert.NotEqual(t, runTune(append(args, "-out", "")), nil)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// RECORD_QUEUE is how many entries may wait for the disk before the
+// recorder starts dropping them rather than slow down a move.
+const RECORD_QUEUE = 1024
+
+// Record is one line of a game log: a request body exactly as we received
+// it and what we did about it.
+type Record struct {
+	Type        string          `json:"type"` // "start", "move" or "end"
+	GameId      string          `json:"game_id"`
+	Snake       string          `json:"snake,omitempty"` // our snake profile, "" for the default
+	Time        time.Time       `json:"time"`
+	Strategy    string          `json:"strategy,omitempty"`
+	Request     json.RawMessage `json:"request"`
+	Response    interface{}     `json:"response,omitempty"`
+	Elapsed     float64         `json:"elapsed_ms,omitempty"`
+	Diagnostics Diagnostics     `json:"diagnostics,omitempty"`
+	Error       string          `json:"error,omitempty"` // set when the answer was a survivalMove
+}
+
+// Recorder appends records to one JSON Lines file per game under dir.
+// Writes happen on a background goroutine so callers never wait on the
+// disk. A nil *Recorder records nothing.
+type Recorder struct {
+	dir     string
+	records chan Record
+	done    chan struct{}
+}
+
+var recorder *Recorder
+
+// NewRecorder creates dir if needed and starts the writer.
+func NewRecorder(dir string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	r := &Recorder{
+		dir:     dir,
+		records: make(chan Record, RECORD_QUEUE),
+		done:    make(chan struct{}),
+	}
+	go r.run()
+	return r, nil
+}
+
+// Record queues a record, dropping it if the writer has fallen too far
+// behind.
+func (r *Recorder) Record(record Record) {
+	if r == nil {
+		return
+	}
+	select {
+	case r.records <- record:
+	default:
+		log.Printf("Recorder queue full, dropping %s record for %s\n", record.Type, record.GameId)
+	}
+}
+
+// Close writes out everything queued and stops the writer.
+func (r *Recorder) Close() {
+	if r == nil {
+		return
+	}
+	close(r.records)
+	<-r.done
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// Path is the log file of one of our snakes in a game: <game>.jsonl for
+// the default snake, <game>.<snake>.jsonl for the others.
+func (r *Recorder) Path(gameId string, snake string) string {
+	if snake != "" {
+		gameId += "." + snake
+	}
+	name := unsafeFileChars.ReplaceAllString(gameId, "_")
+	if name == "" || name == "." || name == ".." {
+		name = "unknown"
+	}
+	return filepath.Join(r.dir, name+".jsonl")
+}
+
+func (r *Recorder) run() {
+	defer close(r.done)
+	for record := range r.records {
+		if err := r.write(record); err != nil {
+			log.Println("Can't record game: ", err)
+		}
+	}
+}
+
+// write opens the game's file for every record, so games that never send
+// /end don't leave files open.
+func (r *Recorder) write(record Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		// Most likely a body that isn't JSON; keep it as a string.
+		quoted, _ := json.Marshal(string(record.Request))
+		record.Request = quoted
+		if line, err = json.Marshal(record); err != nil {
+			return err
+		}
+	}
+	file, err := os.OpenFile(r.Path(record.GameId, record.Snake), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
+
+// ReadRecords loads a game log written by a Recorder.
+func ReadRecords(path string) ([]Record, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	records := []Record{}
+	decoder := json.NewDecoder(file)
+	for decoder.More() {
+		record := Record{}
+		if err := decoder.Decode(&record); err != nil {
+			return records, fmt.Errorf("%s: record %d: %v", path, len(records)+1, err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// Move is the direction we answered with, for move records.
+func (record Record) Move() (Dir, bool) {
+	if record.Response == nil {
+		return UP, false
+	}
+	raw, err := json.Marshal(record.Response)
+	if err != nil {
+		return UP, false
+	}
+	response := MoveResponse{}
+	if json.Unmarshal(raw, &response) != nil {
+		return UP, false
+	}
+	return parseDir(response.Move)
+}
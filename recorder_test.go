@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	assert "gopkg.in/go-playground/assert.v1"
+)
+
+func TestRecorder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "games")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	r, err := NewRecorder(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder = r
+	defer func() { recorder = nil }()
+
+	body := `{"game_id":"../game 1","width":5,"height":5,"you":"me","turn":1,"snakes":[{"id":"me","health_points":90,"coords":[[2,2],[2,3]]}]}`
+	handleMove(httptest.NewRecorder(), httptest.NewRequest("POST", "/food/move", strings.NewReader(body)))
+	handleMove(httptest.NewRecorder(), httptest.NewRequest("POST", "/move", strings.NewReader("not json")))
+	handleEnd(httptest.NewRecorder(), httptest.NewRequest("POST", "/food/end", strings.NewReader(body)))
+	r.Close()
+
+	// The game id can't escape the directory.
+	assert.Equal(t, r.Path("../game 1", ""), dir+"/.._game_1.jsonl")
+	assert.Equal(t, r.Path("../game 1", "food"), dir+"/.._game_1.food.jsonl")
+
+	records := []Record{}
+	for _, name := range []string{r.Path("../game 1", "food"), r.Path("", "")} {
+		file, err := os.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			record := Record{}
+			if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+				t.Fatal(err)
+			}
+			records = append(records, record)
+		}
+		file.Close()
+	}
+
+	assert.Equal(t, len(records), 3)
+	assert.Equal(t, records[0].Type, "move")
+	assert.Equal(t, records[0].Strategy, "food")
+	assert.Equal(t, records[0].Snake, "food")
+	assert.Equal(t, string(records[0].Request), body)
+	assert.NotEqual(t, records[0].Response, nil)
+	assert.Equal(t, records[1].Type, "end")
+	assert.Equal(t, records[2].Type, "move")
+	assert.Equal(t, string(records[2].Request), `"not json"`)
+}
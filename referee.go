@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// A GameWatcher is a Player that wants to hear when a game starts and ends.
+type GameWatcher interface {
+	Start(req *MoveRequest) error
+	End(req *MoveRequest) error
+}
+
+// httpPlayer drives a snake server over HTTP the way the real engine does.
+type httpPlayer struct {
+	url    string
+	api    APIVersion
+	client *http.Client
+}
+
+func isSnakeURL(name string) bool {
+	return strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://")
+}
+
+func NewHTTPPlayer(url string, api APIVersion, timeout time.Duration) Player {
+	return &httpPlayer{
+		url:    strings.TrimRight(url, "/"),
+		api:    api,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// newPlayer resolves a snake URL or a registered strategy name, which
+// gets nodes as for NewTunedPlayer.
+func newPlayer(name string, api APIVersion, timeout time.Duration, nodes int) (Player, error) {
+	if isSnakeURL(name) {
+		return NewHTTPPlayer(name, api, timeout), nil
+	}
+	return NewTunedPlayer(name, config.Params, nodes)
+}
+
+func (p *httpPlayer) Name() string { return p.url }
+
+func (p *httpPlayer) post(action string, body []byte) (io.ReadCloser, error) {
+	res, err := p.client.Post(p.url+"/"+action, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, fmt.Errorf("%s/%s answered %s", p.url, action, res.Status)
+	}
+	return res.Body, nil
+}
+
+func (p *httpPlayer) notify(action string, req *MoveRequest) error {
+	var body []byte
+	var err error
+	if p.api == APIv1 {
+		body, err = EncodeMoveRequest(req, p.api)
+	} else {
+		body, err = json.Marshal(GameStartRequest{GameId: req.GameId, Height: req.Height, Width: req.Width})
+	}
+	if err != nil {
+		return err
+	}
+	res, err := p.post(action, body)
+	if err != nil {
+		return err
+	}
+	io.Copy(ioutil.Discard, res)
+	return res.Close()
+}
+
+func (p *httpPlayer) Start(req *MoveRequest) error { return p.notify("start", req) }
+
+// End is only part of the v1 protocol.
+func (p *httpPlayer) End(req *MoveRequest) error {
+	if p.api != APIv1 {
+		return nil
+	}
+	return p.notify("end", req)
+}
+
+func (p *httpPlayer) Move(req *MoveRequest) (Dir, error) {
+	req.API = p.api
+	req.Timeout = int(p.client.Timeout / time.Millisecond)
+	body, err := EncodeMoveRequest(req, p.api)
+	if err != nil {
+		return UP, err
+	}
+	res, err := p.post("move", body)
+	if err != nil {
+		return UP, err
+	}
+	defer res.Close()
+
+	decoded := MoveResponse{}
+	if err := json.NewDecoder(res).Decode(&decoded); err != nil {
+		return UP, err
+	}
+	dir, ok := parseDir(decoded.Move)
+	if !ok {
+		return UP, fmt.Errorf("%s answered an unknown move %q", p.url, decoded.Move)
+	}
+	return dir, nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	assert "gopkg.in/go-playground/assert.v1"
+)
+
+// snakeServer records what it is sent and answers /move with answer.
+type snakeServer struct {
+	sync.Mutex
+	bodies map[string][][]byte
+	answer func(req *MoveRequest) (int, string)
+}
+
+func newSnakeServer(answer func(req *MoveRequest) (int, string)) (*snakeServer, *httptest.Server) {
+	s := &snakeServer{bodies: map[string][][]byte{}, answer: answer}
+	return s, httptest.NewServer(s)
+}
+
+func (s *snakeServer) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	body, _ := readBody(req)
+	s.Lock()
+	s.bodies[req.URL.Path] = append(s.bodies[req.URL.Path], body)
+	s.Unlock()
+	if req.URL.Path != "/move" {
+		return
+	}
+	decoded, _ := DecodeMoveRequest(body)
+	status, move := s.answer(decoded)
+	res.WriteHeader(status)
+	json.NewEncoder(res).Encode(MoveResponse{Move: move})
+}
+
+func TestHTTPPlayerBodies(t *testing.T) {
+	req := &MoveRequest{
+		GameId: "g",
+		Turn:   4,
+		Width:  7,
+		Height: 5,
+		You:    "me",
+		Snakes: []Snake{{ID: "me", HealthPoints: 90, Coords: []Point{{1, 1}, {1, 2}}}},
+	}
+
+	s, server := newSnakeServer(func(*MoveRequest) (int, string) { return http.StatusOK, "left" })
+	defer server.Close()
+	player := NewHTTPPlayer(server.URL+"/", API2017, time.Second).(*httpPlayer)
+	assert.Equal(t, player.Start(copyRequest(req)), nil)
+	dir, err := player.Move(copyRequest(req))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, dir, LEFT)
+	assert.Equal(t, player.End(copyRequest(req)), nil)
+
+	start := GameStartRequest{}
+	assert.Equal(t, json.Unmarshal(s.bodies["/start"][0], &start), nil)
+	assert.Equal(t, start, GameStartRequest{GameId: "g", Width: 7, Height: 5})
+	move, err := DecodeMoveRequest(s.bodies["/move"][0])
+	assert.Equal(t, err, nil)
+	assert.Equal(t, move.API, API2017)
+	assert.Equal(t, move.Turn, 4)
+	assert.Equal(t, move.Snakes[0].Coords, req.Snakes[0].Coords)
+	// The 2017 engine had no /end.
+	assert.Equal(t, len(s.bodies["/end"]), 0)
+
+	s, server = newSnakeServer(func(*MoveRequest) (int, string) { return http.StatusOK, "down" })
+	defer server.Close()
+	player = NewHTTPPlayer(server.URL, APIv1, 300*time.Millisecond).(*httpPlayer)
+	assert.Equal(t, player.Start(copyRequest(req)), nil)
+	dir, err = player.Move(copyRequest(req))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, dir, DOWN)
+	assert.Equal(t, player.End(copyRequest(req)), nil)
+
+	for _, action := range []string{"/start", "/move", "/end"} {
+		assert.Equal(t, len(s.bodies[action]), 1)
+		decoded, err := DecodeMoveRequest(s.bodies[action][0])
+		assert.Equal(t, err, nil)
+		assert.Equal(t, decoded.API, APIv1)
+		assert.Equal(t, decoded.GameId, "g")
+		assert.Equal(t, decoded.Snakes[0].Coords, req.Snakes[0].Coords)
+		assert.Equal(t, decoded.Me.ID, "me")
+	}
+	move, _ = DecodeMoveRequest(s.bodies["/move"][0])
+	assert.Equal(t, move.Timeout, 300)
+}
+
+func TestHTTPPlayerErrors(t *testing.T) {
+	req := &MoveRequest{
+		Width:  7,
+		Height: 7,
+		You:    "me",
+		Snakes: []Snake{{ID: "me", HealthPoints: 90, Coords: []Point{{1, 1}, {1, 2}}}},
+	}
+	for _, answer := range []struct {
+		status int
+		move   string
+	}{
+		{http.StatusInternalServerError, "up"},
+		{http.StatusOK, "sideways"},
+	} {
+		_, server := newSnakeServer(func(*MoveRequest) (int, string) { return answer.status, answer.move })
+		_, err := NewHTTPPlayer(server.URL, APIv1, time.Second).Move(copyRequest(req))
+		assert.NotEqual(t, err, nil)
+		server.Close()
+	}
+}
+
+func TestHTTPPlayerTimeout(t *testing.T) {
+	// Right on the first move, then too slow to turn up: the engine keeps
+	// it going right.
+	_, server := newSnakeServer(func(req *MoveRequest) (int, string) {
+		if req.Turn > 0 {
+			time.Sleep(200 * time.Millisecond)
+			return http.StatusOK, "up"
+		}
+		return http.StatusOK, "right"
+	})
+	defer server.Close()
+
+	gameConfig := DefaultGameConfig
+	gameConfig.Width, gameConfig.Height = 7, 7
+	gameConfig.Seed = 1
+	gameConfig.MaxTurns = 2
+	g := NewGame(gameConfig, []Player{NewHTTPPlayer(server.URL, APIv1, 50*time.Millisecond), fixedPlayer(LEFT)})
+	g.Run()
+	assert.Equal(t, g.Log[1].Moves["snake-0"], "right")
+	assert.Equal(t, g.Log[2].Moves["snake-0"], "right")
+	assert.Equal(t, g.Log[2].Snakes[0].Coords[0], Point{3, 1})
+}
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Boards are drawn one character per cell, the top row first:
+//
+//	.....
+//	.Aa0*
+//	..>B.
+//	..1b.
+//	turn: 3
+//	you: A
+//	move: right
+//	A: me health=90 length=3
+//	B: them health=100 length=3
+//
+// Snakes are lettered in request order. A head is the uppercase letter,
+// the body the lowercase letter and the tail the snake's index as a digit
+// (lowercase too from the eleventh snake on). '*' is food, '.' an empty
+// cell and ^ > v < mark where our head is going when a move is shown.
+// The lines after the grid give the turn, which snake is us, the move and
+// every snake's id, health and full length, which counts the segments
+// stacked on the tail. Only the first 26 snakes have a letter; any more
+// are drawn as '?', which ParseBoard can't read back.
+const (
+	GLYPH_EMPTY = '.'
+	GLYPH_FOOD  = '*'
+)
+
+var moveGlyphs = [num_dirs]byte{'^', '>', 'v', '<'}
+
+// RenderBoard draws the state in req.
+func RenderBoard(req *MoveRequest) string {
+	return render(req, nil)
+}
+
+// RenderMove draws the state in req with an arrow where our head goes
+// when we play dir.
+func RenderMove(req *MoveRequest, dir Dir) string {
+	return render(req, &dir)
+}
+
+func render(req *MoveRequest, move *Dir) string {
+	grid := make([][]byte, req.Height)
+	for y := range grid {
+		grid[y] = bytes.Repeat([]byte{GLYPH_EMPTY}, req.Width)
+	}
+	inside := func(p Point) bool { return inBounds(req, p) }
+
+	for _, food := range req.Food {
+		if inside(food) {
+			grid[food.Y][food.X] = GLYPH_FOOD
+		}
+	}
+	for i, snake := range req.Snakes {
+		head, body, tail := snakeGlyphs(i)
+		// Body, then tail, then head, so stacked segments show as the
+		// tail and a head stacked on its body still shows as the head.
+		last := len(snake.Coords) - 1
+		for pos := last - 1; pos > 0; pos-- {
+			if p := snake.Coords[pos]; inside(p) {
+				grid[p.Y][p.X] = body
+			}
+		}
+		if last > 0 && inside(snake.Coords[last]) {
+			grid[snake.Coords[last].Y][snake.Coords[last].X] = tail
+		}
+		if last >= 0 && inside(snake.Coords[0]) {
+			grid[snake.Coords[0].Y][snake.Coords[0].X] = head
+		}
+	}
+
+	// Snakes whose head is off the board aren't drawn, so they can't be
+	// described either.
+	drawn := func(i int) bool {
+		return len(req.Snakes[i].Coords) > 0 && inside(req.Snakes[i].Coords[0])
+	}
+	me := -1
+	for i, snake := range req.Snakes {
+		if snake.ID == req.You && drawn(i) {
+			me = i
+		}
+	}
+	if move != nil && me >= 0 {
+		if p := req.Snakes[me].Coords[0].Step(*move); inside(p) && grid[p.Y][p.X] == GLYPH_EMPTY {
+			grid[p.Y][p.X] = moveGlyphs[*move]
+		}
+	}
+
+	var out bytes.Buffer
+	for _, row := range grid {
+		out.Write(row)
+		out.WriteByte('\n')
+	}
+	fmt.Fprintf(&out, "turn: %d\n", req.Turn)
+	if me >= 0 {
+		fmt.Fprintf(&out, "you: %c\n", snakeLetter(me))
+	}
+	if move != nil {
+		fmt.Fprintf(&out, "move: %s\n", directions[*move])
+	}
+	for i, snake := range req.Snakes {
+		if !drawn(i) {
+			continue
+		}
+		fmt.Fprintf(&out, "%c: %s health=%d length=%d\n", snakeLetter(i), snake.ID, snake.HealthPoints, len(snake.Coords))
+	}
+	return out.String()
+}
+
+func snakeLetter(i int) byte {
+	if i < 26 {
+		return byte('A' + i)
+	}
+	return '?'
+}
+
+func snakeGlyphs(i int) (head byte, body byte, tail byte) {
+	head = snakeLetter(i)
+	body = '?'
+	if i < 26 {
+		body = byte('a' + i)
+	}
+	tail = body
+	if i < 10 {
+		tail = byte('0' + i)
+	}
+	return head, body, tail
+}
+
+// ParseBoard reads a board drawn by RenderBoard back into a request. The
+// lines after the grid are optional: ids default to the lowercase letter,
+// health to MAX_HEALTH, length to the cells drawn and you to snake A.
+// Blank lines around the board and indentation common to every line are
+// ignored, so boards can be written inline in Go source.
+func ParseBoard(text string) (*MoveRequest, error) {
+	lines := trimBoardLines(text)
+	grid := []string{}
+	for len(lines) > 0 && !strings.Contains(lines[0], ":") {
+		grid = append(grid, lines[0])
+		lines = lines[1:]
+	}
+	if len(grid) == 0 {
+		return nil, fmt.Errorf("no board")
+	}
+
+	req := &MoveRequest{Width: len(grid[0]), Height: len(grid)}
+	heads := map[byte]Point{}
+	bodies := map[byte][]Point{}
+	tails := map[byte]Point{}
+	for y, row := range grid {
+		if len(row) != req.Width {
+			return nil, fmt.Errorf("row %d is %d wide, not %d", y+1, len(row), req.Width)
+		}
+		for x := 0; x < len(row); x++ {
+			p, c := Point{x, y}, row[x]
+			switch {
+			case c == GLYPH_EMPTY || c == '^' || c == '>' || c == '<':
+			case c == GLYPH_FOOD:
+				req.Food = append(req.Food, p)
+			case c >= 'A' && c <= 'Z':
+				if _, ok := heads[c]; ok {
+					return nil, fmt.Errorf("snake %c has two heads", c)
+				}
+				heads[c] = p
+			case c >= 'a' && c <= 'z':
+				bodies[c-'a'+'A'] = append(bodies[c-'a'+'A'], p)
+			case c >= '0' && c <= '9':
+				if _, ok := tails[c-'0'+'A']; ok {
+					return nil, fmt.Errorf("snake %c has two tails", c-'0'+'A')
+				}
+				tails[c-'0'+'A'] = p
+			default:
+				return nil, fmt.Errorf("unknown cell %q at (%d, %d)", c, x, y)
+			}
+		}
+	}
+	// 'v' is a move arrow unless there's a snake V.
+	if _, ok := heads['V']; !ok {
+		delete(bodies, 'V')
+	}
+
+	index := map[byte]int{}
+	for c := byte('A'); c <= 'Z'; c++ {
+		head, ok := heads[c]
+		if !ok {
+			if _, ok := tails[c]; ok || len(bodies[c]) > 0 {
+				return nil, fmt.Errorf("snake %c has no head", c)
+			}
+			continue
+		}
+		tail, hasTail := tails[c]
+		coords, ok := traceSnake(head, bodies[c], tail, hasTail)
+		if !ok {
+			return nil, fmt.Errorf("can't trace snake %c from head to tail", c)
+		}
+		index[c] = len(req.Snakes)
+		req.Snakes = append(req.Snakes, Snake{
+			ID:           string(c - 'A' + 'a'),
+			HealthPoints: MAX_HEALTH,
+			Coords:       coords,
+		})
+	}
+	you := byte(0)
+	for _, line := range lines {
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%q: not a key: value line", line)
+		}
+		key, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		var err error
+		switch {
+		case key == "turn":
+			req.Turn, err = strconv.Atoi(value)
+		case key == "you":
+			if len(value) != 1 {
+				err = fmt.Errorf("not a snake letter")
+				break
+			}
+			you = value[0]
+		case key == "move":
+			if _, ok := parseDir(value); !ok {
+				err = fmt.Errorf("not a direction")
+			}
+		case len(key) == 1 && key[0] >= 'A' && key[0] <= 'Z':
+			i, ok := index[key[0]]
+			if !ok {
+				err = fmt.Errorf("snake %s isn't on the board", key)
+				break
+			}
+			err = parseSnakeLine(&req.Snakes[i], value)
+		default:
+			err = fmt.Errorf("unknown key")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%q: %v", line, err)
+		}
+	}
+	if you != 0 {
+		i, ok := index[you]
+		if !ok {
+			return nil, fmt.Errorf("you: snake %c isn't on the board", you)
+		}
+		req.You = req.Snakes[i].ID
+	} else if len(req.Snakes) > 0 {
+		req.You = req.Snakes[0].ID
+	}
+	return req, nil
+}
+
+// parseSnakeLine reads "id health=90 length=5" into snake.
+func parseSnakeLine(snake *Snake, line string) error {
+	for i, field := range strings.Fields(line) {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) == 1 {
+			if i > 0 {
+				return fmt.Errorf("unexpected %q", field)
+			}
+			snake.ID = field
+			continue
+		}
+		n, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return err
+		}
+		switch parts[0] {
+		case "health":
+			snake.HealthPoints = n
+		case "length":
+			if n < len(snake.Coords) {
+				return fmt.Errorf("length %d is shorter than the %d cells drawn", n, len(snake.Coords))
+			}
+			for len(snake.Coords) < n {
+				snake.Coords = append(snake.Coords, snake.Coords[len(snake.Coords)-1])
+			}
+		default:
+			return fmt.Errorf("unknown field %q", parts[0])
+		}
+	}
+	return nil
+}
+
+// traceSnake orders a snake's cells into a path from its head through
+// every body cell, ending on the tail when there is one.
+func traceSnake(head Point, body []Point, tail Point, hasTail bool) ([]Point, bool) {
+	left := map[Point]bool{}
+	for _, p := range body {
+		left[p] = true
+	}
+	if hasTail {
+		left[tail] = true
+	}
+
+	path := []Point{head}
+	var extend func() bool
+	extend = func() bool {
+		if len(left) == 0 {
+			return true
+		}
+		last := path[len(path)-1]
+		for dir := UP; dir < num_dirs; dir++ {
+			next := last.Step(dir)
+			if !left[next] || (hasTail && next == tail && len(left) > 1) {
+				continue
+			}
+			delete(left, next)
+			path = append(path, next)
+			if extend() {
+				return true
+			}
+			path = path[:len(path)-1]
+			left[next] = true
+		}
+		return false
+	}
+	return path, extend()
+}
+
+// trimBoardLines splits text into lines without the blank lines around
+// them, trailing spaces or their common indentation.
+func trimBoardLines(text string) []string {
+	lines := strings.Split(text, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " \t\r")
+	}
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	indent := -1
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	for i := range lines {
+		if len(lines[i]) >= indent && indent > 0 {
+			lines[i] = lines[i][indent:]
+		}
+	}
+	return lines
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"testing"
+
+	assert "gopkg.in/go-playground/assert.v1"
+)
+
+func TestRenderBoard(t *testing.T) {
+	req := &MoveRequest{
+		Width:  5,
+		Height: 4,
+		Turn:   3,
+		You:    "me",
+		Food:   []Point{{4, 1}},
+		Snakes: []Snake{
+			{ID: "me", HealthPoints: 90, Coords: []Point{{1, 1}, {2, 1}, {3, 1}}},
+			{ID: "them", HealthPoints: 100, Coords: []Point{{3, 2}, {3, 3}, {2, 3}, {2, 3}}},
+		},
+	}
+	board := `.....
+.Aa0*
+...B.
+..1b.
+turn: 3
+you: A
+A: me health=90 length=3
+B: them health=100 length=4
+`
+	assert.Equal(t, RenderBoard(req), board)
+
+	parsed, err := ParseBoard(board)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, parsed, req)
+
+	move := RenderMove(req, DOWN)
+	assert.Equal(t, move[:24], ".....\n.Aa0*\n.v.B.\n..1b.\n")
+	parsed, err = ParseBoard(move)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, parsed, req)
+}
+
+func TestParseBoard(t *testing.T) {
+	// A coiled snake only traces one way, and the details are optional.
+	req, err := ParseBoard(`
+		.....
+		.bb..
+		.bB1.
+		.bbb.
+		you: B
+	`)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, req.Width, 5)
+	assert.Equal(t, req.Height, 4)
+	assert.Equal(t, req.You, "b")
+	assert.Equal(t, len(req.Snakes), 1)
+	assert.Equal(t, req.Snakes[0].HealthPoints, MAX_HEALTH)
+	assert.Equal(t, req.Snakes[0].Coords, []Point{
+		{2, 2}, {2, 1}, {1, 1}, {1, 2}, {1, 3}, {2, 3}, {3, 3}, {3, 2},
+	})
+
+	for _, bad := range []string{
+		"",
+		"..\n...",
+		"A.\n.A",
+		"Aa.\n..a",
+		".a0",
+		"A#",
+		"A.\nB: x",
+		"A.\nA: me length=0",
+		"A.\nyou: C",
+		"A.\ncolor: red",
+		"A0\nturn: 1\nbogus\n",
+	} {
+		_, err := ParseBoard(bad)
+		assert.NotEqual(t, err, nil)
+	}
+}
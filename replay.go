@@ -0,0 +1,123 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Change is a recorded turn where the current build answers differently.
+type Change struct {
+	GameId      string
+	Turn        int
+	Recorded    Dir
+	Now         Dir
+	Diagnostics Diagnostics
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s turn %d: recorded %s, now %s %v", c.GameId, c.Turn, directions[c.Recorded], directions[c.Now], c.Diagnostics)
+}
+
+// Replay feeds the move records of one game back through a strategy, with
+// the session history rebuilt from the recorded moves, and returns every
+// turn where it picks a different move. A nil strategy replays each turn
+// with the strategy that was recorded for it. budget 0 uses moveBudget.
+func Replay(records []Record, strategy Strategy, budget time.Duration) (changes []Change, turns int) {
+	session := &Session{}
+	for _, record := range records {
+		if record.Type == "start" {
+			session = &Session{GameId: record.GameId}
+		}
+		if record.Type != "move" {
+			continue
+		}
+		if record.Error != "" {
+			// A survivalMove for a request we choked on is no decision to
+			// compare against.
+			continue
+		}
+		recorded, ok := record.Move()
+		if !ok {
+			continue
+		}
+		req, err := DecodeMoveRequest(record.Request)
+		if err != nil {
+			// Requests we couldn't read at the time still can't be read.
+			continue
+		}
+		if len(getSnake(req, req.You).Coords) == 0 {
+			// Without us on the board there was nothing to decide.
+			continue
+		}
+
+		turnBudget := budget
+		if turnBudget == 0 {
+			turnBudget = moveBudget(req)
+		}
+		data := NewTurnData(req, time.Now().Add(turnBudget))
+		data.history = session.History()
+		if profile, ok := config.Snakes[record.Snake]; ok {
+			data.params = profile.Params
+		}
+
+		s := strategy
+		if s == nil {
+			_, s = selectStrategy("/" + record.Strategy + "/move")
+		}
+		dir, diagnostics, _ := decide(s, data)
+		// Later turns should see the history the game really had.
+		session.Record(data, recorded)
+
+		turns++
+		if dir != recorded {
+			changes = append(changes, Change{
+				GameId:      req.GameId,
+				Turn:        req.Turn,
+				Recorded:    recorded,
+				Now:         dir,
+				Diagnostics: diagnostics,
+			})
+		}
+	}
+	return changes, turns
+}
+
+// runReplay re-runs recorded games through the current build and prints
+// every turn where the decision changed.
+//
+//	battlesnake-go replay -strategy minimax games/*.jsonl
+func runReplay(args []string) error {
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	name := flags.String("strategy", "", "strategy to replay with (default: the one recorded for each turn)")
+	budget := flags.Duration("budget", 0, "time per move (default: as for a live move, see MOVE_DEADLINE)")
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		return fmt.Errorf("usage: replay [-strategy name] [-budget duration] game.jsonl...")
+	}
+
+	var strategy Strategy
+	if *name != "" {
+		var ok bool
+		if strategy, ok = lookupStrategy(*name); !ok {
+			return fmt.Errorf("unknown strategy %q", *name)
+		}
+	}
+
+	total, changed := 0, 0
+	for _, path := range flags.Args() {
+		records, err := ReadRecords(path)
+		if err != nil {
+			return err
+		}
+		changes, turns := Replay(records, strategy, *budget)
+		for _, change := range changes {
+			fmt.Println(change)
+		}
+		total += turns
+		changed += len(changes)
+	}
+	fmt.Fprintf(os.Stderr, "%d of %d turns changed\n", changed, total)
+	return nil
+}
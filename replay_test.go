@@ -0,0 +1,65 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	assert "gopkg.in/go-playground/assert.v1"
+)
+
+func TestReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "games")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	r, err := NewRecorder(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r.Record(Record{Type: "start", GameId: "game", Request: []byte(`{"game_id":"game"}`)})
+	for turn, move := range []string{"left", "left", "up"} {
+		req := &MoveRequest{GameId: "game", Width: 9, Height: 9, Turn: turn, You: "me", Snakes: []Snake{
+			{ID: "me", HealthPoints: 90, Coords: []Point{{6 - turn, 4}, {7 - turn, 4}}},
+		}}
+		body, err := EncodeMoveRequest(req, APIv1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Record(Record{Type: "move", GameId: "game", Request: body, Response: NewMoveResponse(APIv1, move, nil)})
+	}
+	// A move for a snake that isn't on the board is skipped.
+	ghost, err := EncodeMoveRequest(&MoveRequest{GameId: "game", Width: 9, Height: 9, Turn: 3, You: "ghost"}, APIv1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Record(Record{Type: "move", GameId: "game", Request: ghost, Response: NewMoveResponse(APIv1, "up", nil)})
+	// So is one we answered with a survival move.
+	choked, err := EncodeMoveRequest(&MoveRequest{GameId: "game", Width: 9, Height: 9, Turn: 3, You: "me", Snakes: []Snake{
+		{ID: "me", HealthPoints: 90, Coords: []Point{{3, 4}, {4, 4}}},
+	}}, APIv1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Record(Record{Type: "move", GameId: "game", Request: choked, Response: NewMoveResponse(APIv1, "down", nil), Error: "panic: boom"})
+	r.Record(Record{Type: "end", GameId: "game", Request: []byte(`{}`)})
+	r.Close()
+
+	records, err := ReadRecords(r.Path("game", ""))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(records), 7)
+
+	seen := []int{}
+	left := StrategyFunc(func(data *TurnData) (Dir, Diagnostics, error) {
+		seen = append(seen, len(data.history.Moves))
+		return LEFT, Diagnostics{"why": "always"}, nil
+	})
+	changes, turns := Replay(records, left, time.Second)
+	assert.Equal(t, turns, 3)
+	assert.Equal(t, seen, []int{0, 1, 2})
+	assert.Equal(t, changes, []Change{{GameId: "game", Turn: 2, Recorded: UP, Now: LEFT, Diagnostics: Diagnostics{"why": "always"}}})
+	assert.Equal(t, changes[0].String(), "game turn 2: recorded up, now left map[why:always]")
+}
@@ -0,0 +1,194 @@
+package main
+
+// The game rules, shared by the local engine, the searches and the tests.
+// A turn plays out as in the standard ruleset: every snake moves, loses
+// health, eats, and then snakes are eliminated, first for starving or
+// leaving the board and then for collisions with the snakes still left.
+
+const (
+	MAX_HEALTH    = 100
+	HAZARD_DAMAGE = 14
+)
+
+const (
+	CAUSE_WALL         = "wall"
+	CAUSE_STARVATION   = "starvation"
+	CAUSE_SELF         = "self-collision"
+	CAUSE_BODY         = "body-collision"
+	CAUSE_HEAD_TO_HEAD = "head-to-head"
+)
+
+type Elimination struct {
+	Snake string `json:"snake"`
+	Cause string `json:"cause"`
+	By    string `json:"by,omitempty"`
+	Turn  int    `json:"turn"`
+}
+
+func copyRequest(req *MoveRequest) *MoveRequest {
+	c := *req
+	c.Food = append([]Point(nil), req.Food...)
+	c.Hazards = append([]Point(nil), req.Hazards...)
+	c.Snakes = make([]Snake, len(req.Snakes))
+	for i, snake := range req.Snakes {
+		c.Snakes[i] = snake
+		c.Snakes[i].Coords = append([]Point(nil), snake.Coords...)
+	}
+	return &c
+}
+
+func alive(state *MoveRequest, id string) bool {
+	return len(getSnake(state, id).Coords) > 0
+}
+
+// defaultMove is what a snake does without orders: keep going the way it
+// was going, or up if it hasn't moved yet.
+func defaultMove(snake Snake) Dir {
+	if len(snake.Coords) > 1 {
+		for dir := UP; dir < num_dirs; dir++ {
+			if snake.Coords[1].Step(dir) == snake.Coords[0] {
+				return dir
+			}
+		}
+	}
+	return UP
+}
+
+func inBounds(state *MoveRequest, p Point) bool {
+	return p.X >= 0 && p.Y >= 0 && p.X < state.Width && p.Y < state.Height
+}
+
+// advance plays one turn on state with a move per snake; snakes missing
+// from moves take their defaultMove. It returns the snakes it removed.
+func advance(state *MoveRequest, moves map[string]Dir) []Elimination {
+	moveSnakes(state, moves)
+	return removeEliminated(state)
+}
+
+// moveSnakes is the first half of a turn: every snake moves, loses health
+// and eats, but nobody is removed yet.
+func moveSnakes(state *MoveRequest, moves map[string]Dir) {
+	for i := range state.Snakes {
+		snake := &state.Snakes[i]
+		dir, ok := moves[snake.ID]
+		if !ok {
+			dir = defaultMove(*snake)
+		}
+		head := snake.Coords[0].Step(dir)
+		snake.Coords = append([]Point{head}, snake.Coords[:len(snake.Coords)-1]...)
+		snake.HealthPoints--
+	}
+
+	for i := range state.Snakes {
+		snake := &state.Snakes[i]
+		for _, hazard := range state.Hazards {
+			if hazard == snake.Coords[0] {
+				snake.HealthPoints -= HAZARD_DAMAGE
+			}
+		}
+	}
+
+	eaten := map[Point]bool{}
+	for i := range state.Snakes {
+		snake := &state.Snakes[i]
+		for _, food := range state.Food {
+			if food == snake.Coords[0] {
+				eaten[food] = true
+				snake.HealthPoints = MAX_HEALTH
+				snake.Coords = append(snake.Coords, snake.Coords[len(snake.Coords)-1])
+			}
+		}
+	}
+	food := state.Food[:0]
+	for _, f := range state.Food {
+		if !eaten[f] {
+			food = append(food, f)
+		}
+	}
+	state.Food = food
+}
+
+// removeEliminated is the second half of a turn: it takes the snakes that
+// can't stay off the board and returns them.
+func removeEliminated(state *MoveRequest) []Elimination {
+	eliminated := eliminations(state)
+	dead := map[string]bool{}
+	for _, e := range eliminated {
+		dead[e.Snake] = true
+	}
+	alive := state.Snakes[:0]
+	for _, snake := range state.Snakes {
+		if !dead[snake.ID] {
+			alive = append(alive, snake)
+		}
+	}
+	state.Snakes = alive
+	return eliminated
+}
+
+// eliminations lists the snakes in state that can't stay on the board.
+// Snakes that starved or left the board are out before collisions are
+// checked, so nobody runs into them.
+func eliminations(state *MoveRequest) []Elimination {
+	eliminated := []Elimination{}
+	remaining := []Snake{}
+	for _, snake := range state.Snakes {
+		switch {
+		case snake.HealthPoints <= 0:
+			eliminated = append(eliminated, Elimination{Snake: snake.ID, Cause: CAUSE_STARVATION, Turn: state.Turn})
+		case !inBounds(state, snake.Coords[0]):
+			eliminated = append(eliminated, Elimination{Snake: snake.ID, Cause: CAUSE_WALL, Turn: state.Turn})
+		default:
+			remaining = append(remaining, snake)
+		}
+	}
+
+	for _, snake := range remaining {
+		if cause, by := collision(remaining, snake); cause != "" {
+			eliminated = append(eliminated, Elimination{Snake: snake.ID, Cause: cause, By: by, Turn: state.Turn})
+		}
+	}
+	return eliminated
+}
+
+// moveBlocked reports whether moving snake id in dir takes it off the
+// board or into a body once moveSnakes has moved everybody, the others by
+// their defaultMove. Their heads can't be known in advance, so neither
+// starving nor head-to-head collisions count.
+func moveBlocked(state *MoveRequest, id string, dir Dir) bool {
+	next := copyRequest(state)
+	moveSnakes(next, map[string]Dir{id: dir})
+	snake := getSnake(next, id)
+	if !inBounds(next, snake.Coords[0]) {
+		return true
+	}
+	cause, _ := collision(next.Snakes, snake)
+	return cause == CAUSE_SELF || cause == CAUSE_BODY
+}
+
+// collision returns why snake's head can't be where it is among snakes,
+// and which snake it ran into.
+func collision(snakes []Snake, snake Snake) (cause string, by string) {
+	head := snake.Coords[0]
+	for _, body := range snake.Coords[1:] {
+		if body == head {
+			return CAUSE_SELF, ""
+		}
+	}
+	for _, other := range snakes {
+		if other.ID == snake.ID {
+			continue
+		}
+		for _, body := range other.Coords[1:] {
+			if body == head {
+				return CAUSE_BODY, other.ID
+			}
+		}
+	}
+	for _, other := range snakes {
+		if other.ID != snake.ID && other.Coords[0] == head && len(other.Coords) >= len(snake.Coords) {
+			return CAUSE_HEAD_TO_HEAD, other.ID
+		}
+	}
+	return "", ""
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"testing"
+
+	assert "gopkg.in/go-playground/assert.v1"
+)
+
+func rulesState(snakes ...Snake) *MoveRequest {
+	return &MoveRequest{Width: 7, Height: 7, Turn: 3, Snakes: snakes}
+}
+
+func TestAdvanceMoves(t *testing.T) {
+	state := rulesState(Snake{ID: "a", HealthPoints: 50, Coords: []Point{{3, 3}, {3, 4}, {3, 5}}})
+	eliminated := advance(state, map[string]Dir{"a": LEFT})
+
+	assert.Equal(t, len(eliminated), 0)
+	assert.Equal(t, state.Snakes[0].Coords, []Point{{2, 3}, {3, 3}, {3, 4}})
+	assert.Equal(t, state.Snakes[0].HealthPoints, 49)
+
+	// Without orders a snake keeps going the way it was going.
+	advance(state, map[string]Dir{})
+	assert.Equal(t, state.Snakes[0].Coords, []Point{{1, 3}, {2, 3}, {3, 3}})
+}
+
+func TestAdvanceFeeds(t *testing.T) {
+	state := rulesState(
+		Snake{ID: "a", HealthPoints: 10, Coords: []Point{{3, 3}, {3, 4}, {3, 5}}},
+		Snake{ID: "b", HealthPoints: 10, Coords: []Point{{0, 0}, {1, 0}}},
+	)
+	state.Food = []Point{{3, 2}, {6, 6}}
+	advance(state, map[string]Dir{"a": UP, "b": DOWN})
+
+	a := getSnake(state, "a")
+	assert.Equal(t, a.HealthPoints, MAX_HEALTH)
+	// The tail stays put for a turn while the snake grows.
+	assert.Equal(t, a.Coords, []Point{{3, 2}, {3, 3}, {3, 4}, {3, 4}})
+	assert.Equal(t, state.Food, []Point{{6, 6}})
+	assert.Equal(t, getSnake(state, "b").HealthPoints, 9)
+
+	advance(state, map[string]Dir{"a": UP, "b": DOWN})
+	assert.Equal(t, getSnake(state, "a").Coords, []Point{{3, 1}, {3, 2}, {3, 3}, {3, 4}})
+}
+
+func TestAdvanceSharedFood(t *testing.T) {
+	state := rulesState(
+		Snake{ID: "a", HealthPoints: 10, Coords: []Point{{2, 3}, {1, 3}, {0, 3}}},
+		Snake{ID: "b", HealthPoints: 10, Coords: []Point{{4, 3}, {5, 3}, {6, 3}, {6, 4}}},
+	)
+	state.Food = []Point{{3, 3}}
+	eliminated := advance(state, map[string]Dir{"a": RIGHT, "b": LEFT})
+
+	// Both eat, then the longer one wins the head-on collision.
+	assert.Equal(t, len(state.Food), 0)
+	assert.Equal(t, eliminated, []Elimination{{Snake: "a", Cause: CAUSE_HEAD_TO_HEAD, By: "b", Turn: 3}})
+	assert.Equal(t, len(getSnake(state, "b").Coords), 5)
+	assert.Equal(t, getSnake(state, "b").HealthPoints, MAX_HEALTH)
+}
+
+func TestAdvanceEliminations(t *testing.T) {
+	cases := []struct {
+		name   string
+		snakes []Snake
+		moves  map[string]Dir
+		out    []Elimination
+	}{
+		{
+			"wall",
+			[]Snake{{ID: "a", HealthPoints: 50, Coords: []Point{{0, 3}, {1, 3}}}},
+			map[string]Dir{"a": LEFT},
+			[]Elimination{{Snake: "a", Cause: CAUSE_WALL, Turn: 3}},
+		},
+		{
+			"starvation",
+			[]Snake{{ID: "a", HealthPoints: 1, Coords: []Point{{3, 3}, {4, 3}}}},
+			map[string]Dir{"a": LEFT},
+			[]Elimination{{Snake: "a", Cause: CAUSE_STARVATION, Turn: 3}},
+		},
+		{
+			"self",
+			[]Snake{{ID: "a", HealthPoints: 50, Coords: []Point{{3, 3}, {3, 4}, {4, 4}, {4, 3}, {4, 2}}}},
+			map[string]Dir{"a": RIGHT},
+			[]Elimination{{Snake: "a", Cause: CAUSE_SELF, Turn: 3}},
+		},
+		{
+			"body",
+			[]Snake{
+				{ID: "a", HealthPoints: 50, Coords: []Point{{3, 3}, {2, 3}}},
+				{ID: "b", HealthPoints: 50, Coords: []Point{{4, 2}, {4, 3}, {4, 4}}},
+			},
+			map[string]Dir{"a": RIGHT, "b": UP},
+			[]Elimination{{Snake: "a", Cause: CAUSE_BODY, By: "b", Turn: 3}},
+		},
+		{
+			"head-to-head tie",
+			[]Snake{
+				{ID: "a", HealthPoints: 50, Coords: []Point{{2, 3}, {1, 3}}},
+				{ID: "b", HealthPoints: 50, Coords: []Point{{4, 3}, {5, 3}}},
+			},
+			map[string]Dir{"a": RIGHT, "b": LEFT},
+			[]Elimination{
+				{Snake: "a", Cause: CAUSE_HEAD_TO_HEAD, By: "b", Turn: 3},
+				{Snake: "b", Cause: CAUSE_HEAD_TO_HEAD, By: "a", Turn: 3},
+			},
+		},
+		{
+			"chasing a tail",
+			[]Snake{
+				{ID: "a", HealthPoints: 50, Coords: []Point{{3, 3}, {2, 3}}},
+				{ID: "b", HealthPoints: 50, Coords: []Point{{4, 2}, {4, 3}}},
+			},
+			map[string]Dir{"a": RIGHT, "b": UP},
+			[]Elimination{},
+		},
+		{
+			"a stacked tail doesn't move",
+			[]Snake{
+				{ID: "a", HealthPoints: 50, Coords: []Point{{3, 3}, {2, 3}}},
+				{ID: "b", HealthPoints: 50, Coords: []Point{{4, 2}, {4, 3}, {4, 3}}},
+			},
+			map[string]Dir{"a": RIGHT, "b": UP},
+			[]Elimination{{Snake: "a", Cause: CAUSE_BODY, By: "b", Turn: 3}},
+		},
+		{
+			"snakes off the board don't block",
+			[]Snake{
+				{ID: "a", HealthPoints: 50, Coords: []Point{{1, 1}, {2, 1}}},
+				{ID: "b", HealthPoints: 50, Coords: []Point{{0, 0}, {0, 1}, {0, 2}}},
+			},
+			map[string]Dir{"a": LEFT, "b": UP},
+			[]Elimination{{Snake: "b", Cause: CAUSE_WALL, Turn: 3}},
+		},
+	}
+
+	for _, c := range cases {
+		state := rulesState(c.snakes...)
+		eliminated := advance(state, c.moves)
+		if !assert.IsEqual(eliminated, c.out) {
+			t.Errorf("%s: eliminated %v, want %v", c.name, eliminated, c.out)
+		}
+		if len(state.Snakes) != len(c.snakes)-len(c.out) {
+			t.Errorf("%s: %d snakes left", c.name, len(state.Snakes))
+		}
+	}
+}
+
+func TestAdvanceHazards(t *testing.T) {
+	state := rulesState(Snake{ID: "a", HealthPoints: 50, Coords: []Point{{3, 3}, {3, 4}}})
+	state.Hazards = []Point{{3, 2}}
+	advance(state, map[string]Dir{"a": UP})
+	assert.Equal(t, state.Snakes[0].HealthPoints, 50-1-HAZARD_DAMAGE)
+}
+
+func TestMoveBlocked(t *testing.T) {
+	// a curls round to its tail at (0, 0) with b's body just below it.
+	state := rulesState(
+		Snake{ID: "a", HealthPoints: 50, Coords: []Point{{0, 1}, {1, 1}, {1, 0}, {0, 0}}},
+		Snake{ID: "b", HealthPoints: 50, Coords: []Point{{0, 3}, {0, 2}, {1, 2}}},
+	)
+	// The wall, our neck and b's body are in the way, our tail moves on...
+	assert.Equal(t, moveBlocked(state, "a", LEFT), true)
+	assert.Equal(t, moveBlocked(state, "a", RIGHT), true)
+	assert.Equal(t, moveBlocked(state, "a", DOWN), true)
+	assert.Equal(t, moveBlocked(state, "a", UP), false)
+
+	// ...unless we have just eaten.
+	state.Snakes[0].Coords = append(state.Snakes[0].Coords, Point{0, 0})
+	assert.Equal(t, moveBlocked(state, "a", UP), true)
+}
+
+func TestCopyRequest(t *testing.T) {
+	state := rulesState(Snake{ID: "a", HealthPoints: 50, Coords: []Point{{3, 3}, {3, 4}}})
+	state.Food = []Point{{3, 2}}
+	c := copyRequest(state)
+	advance(c, map[string]Dir{"a": UP})
+
+	assert.Equal(t, state.Snakes[0].Coords, []Point{{3, 3}, {3, 4}})
+	assert.Equal(t, state.Food, []Point{{3, 2}})
+	assert.Equal(t, len(c.Food), 0)
+}
@@ -0,0 +1,168 @@
+package main
+
+import "testing"
+
+// A scenario is a board, drawn as for ParseBoard, and what our snake may
+// or may not do on it.
+type scenario struct {
+	name      string
+	board     string
+	allowed   []Dir // if set, the move must be one of these
+	forbidden []Dir
+	skip      map[string]string // strategies known to get it wrong, and why
+}
+
+var scenarios = []scenario{
+	{
+		name: "wall",
+		board: `
+			.......
+			.......
+			A......
+			a......
+			0......
+			.......
+			.......
+			A: me health=90
+		`,
+		forbidden: []Dir{LEFT},
+	},
+	{
+		name: "only way out",
+		board: `
+			.Aaa0..
+			1bbbbB.
+			.......
+			.......
+			.......
+			.......
+			.......
+			A: me health=90
+			B: them health=90
+		`,
+		allowed: []Dir{LEFT},
+	},
+	{
+		name: "chase own tail",
+		board: `
+			Aa.....
+			0a.....
+			.......
+			.......
+			.......
+			.......
+			.......
+			A: me health=90
+		`,
+		allowed: []Dir{DOWN},
+	},
+	{
+		name: "tail of a snake that just ate",
+		board: `
+			.b1A...
+			.B.a...
+			...0...
+			.......
+			.......
+			.......
+			.......
+			A: me health=90
+			B: them health=100 length=4
+		`,
+		forbidden: []Dir{LEFT},
+	},
+	{
+		name: "dead-end corridor",
+		board: `
+			.bB......
+			.b.......
+			.b.......
+			.bbbbb1..
+			A........
+			a........
+			0........
+			.........
+			.........
+			A: me health=90
+			B: them health=90
+		`,
+		forbidden: []Dir{UP},
+		skip: map[string]string{
+			"food": "with no food in sight it takes the first safe move",
+		},
+	},
+	{
+		name: "head-on with a longer snake",
+		board: `
+			1bbb...
+			...B...
+			.......
+			...A...
+			...a...
+			...0...
+			.......
+			A: me health=90
+			B: them health=90
+		`,
+		forbidden: []Dir{UP},
+	},
+	{
+		name: "food in a pocket",
+		board: `
+			*bB......
+			.b.......
+			.b.......
+			.bbbbb1..
+			A........
+			a........
+			0........
+			.........
+			.........
+			A: me health=60
+			B: them health=90
+		`,
+		forbidden: []Dir{UP},
+		skip: map[string]string{
+			"food": "goes for the nearest food whatever the space around it",
+		},
+	},
+}
+
+func TestScenarios(t *testing.T) {
+	for _, s := range scenarios {
+		req, err := ParseBoard(s.board)
+		if err != nil {
+			t.Errorf("%s: %v", s.name, err)
+			continue
+		}
+		for _, name := range strategyNames() {
+			if _, skip := s.skip[name]; skip {
+				continue
+			}
+			strategy, _ := lookupStrategy(name)
+			// A strategy that gives up is judged on the fallback move, as
+			// it would be in a real game.
+			dir, diagnostics, err := decide(strategy, testTurn(copyRequest(req)))
+			if !s.accepts(dir) {
+				t.Errorf("%s with %s: bad move %s %v %v\n%s", s.name, name, directions[dir], diagnostics, err, RenderMove(req, dir))
+			}
+		}
+	}
+}
+
+func (s scenario) accepts(dir Dir) bool {
+	for _, bad := range s.forbidden {
+		if dir == bad {
+			return false
+		}
+	}
+	if len(s.allowed) == 0 {
+		return true
+	}
+	for _, good := range s.allowed {
+		if dir == good {
+			return true
+		}
+	}
+	return false
+}
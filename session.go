@@ -0,0 +1,138 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// SESSION_IDLE is how long a game may go without a request before its
+// session is dropped, for engines that never call /end.
+const SESSION_IDLE = 10 * time.Minute
+
+// History is what a strategy can see of earlier turns in the same game.
+type History struct {
+	Previous      *MoveRequest // nil on the first move
+	PreviousBoard *Board
+	Moves         []Dir       // our answers so far, oldest first
+	Plan          interface{} // whatever the strategy saved with SavePlan
+}
+
+// LastMove returns the direction the snake with the given id moved to
+// reach its position in req, if it was on the previous board.
+func (h History) LastMove(req *MoveRequest, id string) (Dir, bool) {
+	if h.Previous == nil {
+		return UP, false
+	}
+	before := getSnake(h.Previous, id)
+	now := getSnake(req, id)
+	if len(before.Coords) == 0 || len(now.Coords) == 0 {
+		return UP, false
+	}
+	for dir := UP; dir < num_dirs; dir++ {
+		if before.Coords[0].Step(dir) == now.Coords[0] {
+			return dir, true
+		}
+	}
+	return UP, false
+}
+
+// Session keeps state for one game across requests.
+type Session struct {
+	sync.Mutex
+	GameId   string
+	Started  time.Time
+	lastSeen time.Time
+	history  History
+}
+
+// History returns a copy of the session's history that is safe to hand to
+// a strategy.
+func (s *Session) History() History {
+	s.Lock()
+	defer s.Unlock()
+	h := s.history
+	h.Moves = append([]Dir(nil), s.history.Moves...)
+	return h
+}
+
+// Record stores this turn and our answer to it.
+func (s *Session) Record(data *TurnData, dir Dir) {
+	s.Lock()
+	defer s.Unlock()
+	s.lastSeen = time.Now()
+	s.history.Previous = data.req
+	s.history.PreviousBoard = data.board
+	s.history.Moves = append(s.history.Moves, dir)
+	s.history.Plan = data.savedPlan()
+}
+
+// SessionStore holds the sessions of every game in progress, keyed by
+// game id.
+type SessionStore struct {
+	sync.Mutex
+	sessions map[string]*Session
+	idle     time.Duration
+}
+
+func NewSessionStore(idle time.Duration) *SessionStore {
+	return &SessionStore{sessions: map[string]*Session{}, idle: idle}
+}
+
+var sessions = NewSessionStore(SESSION_IDLE)
+
+// Start begins a fresh session for the game, replacing any earlier one.
+func (store *SessionStore) Start(gameId string) *Session {
+	store.Lock()
+	defer store.Unlock()
+	now := time.Now()
+	session := &Session{GameId: gameId, Started: now, lastSeen: now}
+	store.sessions[gameId] = session
+	return session
+}
+
+// Get returns the game's session, starting one if /start was missed.
+func (store *SessionStore) Get(gameId string) *Session {
+	store.Lock()
+	session, ok := store.sessions[gameId]
+	store.Unlock()
+	if !ok {
+		return store.Start(gameId)
+	}
+	session.Lock()
+	session.lastSeen = time.Now()
+	session.Unlock()
+	return session
+}
+
+func (store *SessionStore) End(gameId string) {
+	store.Lock()
+	defer store.Unlock()
+	delete(store.sessions, gameId)
+}
+
+func (store *SessionStore) Len() int {
+	store.Lock()
+	defer store.Unlock()
+	return len(store.sessions)
+}
+
+// Expire drops sessions that have been idle since before now-idle.
+func (store *SessionStore) Expire(now time.Time) {
+	store.Lock()
+	defer store.Unlock()
+	for id, session := range store.sessions {
+		session.Lock()
+		idle := now.Sub(session.lastSeen)
+		session.Unlock()
+		if idle > store.idle {
+			delete(store.sessions, id)
+		}
+	}
+}
+
+// Janitor expires idle sessions every interval, forever.
+func (store *SessionStore) Janitor(interval time.Duration) {
+	for now := range time.Tick(interval) {
+		store.Expire(now)
+	}
+}
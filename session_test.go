@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	assert "gopkg.in/go-playground/assert.v1"
+)
+
+func TestSessionStore(t *testing.T) {
+	store := NewSessionStore(time.Minute)
+	session := store.Start("game")
+	assert.Equal(t, store.Get("game"), session)
+	assert.Equal(t, store.Get("game").History().Previous == nil, true)
+
+	first := &MoveRequest{Width: 5, Height: 5, You: "me", Snakes: []Snake{
+		{ID: "me", Coords: []Point{{2, 2}, {2, 3}}},
+		{ID: "them", Coords: []Point{{0, 0}, {1, 0}}},
+	}}
+	data := NewTurnData(first, time.Time{})
+	data.SavePlan("corner")
+	session.Record(data, UP)
+
+	second := &MoveRequest{Width: 5, Height: 5, You: "me", Snakes: []Snake{
+		{ID: "me", Coords: []Point{{2, 1}, {2, 2}}},
+		{ID: "them", Coords: []Point{{0, 1}, {0, 0}}},
+	}}
+	history := store.Get("game").History()
+	assert.Equal(t, history.Previous, first)
+	assert.Equal(t, history.Moves, []Dir{UP})
+	assert.Equal(t, history.Plan, "corner")
+	dir, ok := history.LastMove(second, "them")
+	assert.Equal(t, ok, true)
+	assert.Equal(t, dir, DOWN)
+	_, ok = history.LastMove(second, "gone")
+	assert.Equal(t, ok, false)
+
+	// Missing /start still gets a session, /end drops it.
+	assert.NotEqual(t, store.Get("other"), nil)
+	assert.Equal(t, store.Len(), 2)
+	store.End("game")
+	assert.Equal(t, store.Len(), 1)
+
+	store.Expire(time.Now().Add(30 * time.Second))
+	assert.Equal(t, store.Len(), 1)
+	store.Expire(time.Now().Add(2 * time.Minute))
+	assert.Equal(t, store.Len(), 0)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// runSimulate plays local games between registered strategies and snake
+// servers, and prints who won each one.
+//
+//	battlesnake-go simulate -snakes greedy,http://localhost:8080 -games 10 -log games.jsonl
+func runSimulate(args []string) error {
+	flags := flag.NewFlagSet("simulate", flag.ExitOnError)
+	snakes := flags.String("snakes", DEFAULT_STRATEGY+","+DEFAULT_STRATEGY, "comma separated strategy names or snake server URLs, one per snake")
+	games := flags.Int("games", 1, "number of games to play")
+	seed := flags.Int64("seed", 1, "seed of the first game, incremented for each following game")
+	width := flags.Int("width", DefaultGameConfig.Width, "board width")
+	height := flags.Int("height", DefaultGameConfig.Height, "board height")
+	maxTurns := flags.Int("max-turns", 0, "stop a game after this many turns (0 for no limit)")
+	logPath := flags.String("log", "", "write the turn-by-turn log as JSON lines to this file (- for stdout)")
+	timeout := flags.Duration("timeout", 500*time.Millisecond, "how long snake servers get to answer")
+	api := flags.String("api", "v1", "protocol spoken to snake servers: v1 or 2017")
+	flags.Parse(args)
+
+	protocol := APIv1
+	switch *api {
+	case "v1":
+	case "2017":
+		protocol = API2017
+	default:
+		return fmt.Errorf("unknown api %q", *api)
+	}
+
+	var turnLog io.Writer
+	if *logPath == "-" {
+		turnLog = os.Stdout
+	} else if *logPath != "" {
+		file, err := os.Create(*logPath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		turnLog = file
+	}
+
+	names := strings.Split(*snakes, ",")
+	wins := map[string]int{}
+	for i := 0; i < *games; i++ {
+		players := make([]Player, len(names))
+		for j, name := range names {
+			player, err := newPlayer(strings.TrimSpace(name), protocol, *timeout, 0)
+			if err != nil {
+				return err
+			}
+			players[j] = player
+		}
+
+		gameConfig := DefaultGameConfig
+		gameConfig.Width, gameConfig.Height = *width, *height
+		gameConfig.Seed = *seed + int64(i)
+		gameConfig.MaxTurns = *maxTurns
+		game := NewGame(gameConfig, players)
+		result := game.Run()
+
+		if turnLog != nil {
+			encoder := json.NewEncoder(turnLog)
+			for _, turn := range game.Log {
+				if err := encoder.Encode(turn); err != nil {
+					return err
+				}
+			}
+		}
+
+		winner := "draw"
+		if result.Winner != "" {
+			winner = fmt.Sprintf("%s (%s)", result.Winner, game.Players[result.Winner].Name())
+		}
+		wins[result.Winner]++
+		fmt.Fprintf(os.Stderr, "game %d (seed %d): %s after %d turns\n", i+1, gameConfig.Seed, winner, result.Turns)
+	}
+	// Every player in seat order, then the draws.
+	for j, name := range names {
+		id := fmt.Sprintf("snake-%d", j)
+		fmt.Fprintf(os.Stderr, "%s (%s): %d/%d\n", id, strings.TrimSpace(name), wins[id], *games)
+	}
+	fmt.Fprintf(os.Stderr, "draw: %d/%d\n", wins[""], *games)
+	return nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// Diagnostics carries whatever a strategy wants to report about a decision.
+type Diagnostics map[string]interface{}
+
+// A Strategy picks our move for one turn.
+type Strategy interface {
+	Move(data *TurnData) (Dir, Diagnostics, error)
+}
+
+// StrategyFunc lets a plain function be used as a Strategy.
+type StrategyFunc func(data *TurnData) (Dir, Diagnostics, error)
+
+func (f StrategyFunc) Move(data *TurnData) (Dir, Diagnostics, error) { return f(data) }
+
+const DEFAULT_STRATEGY = "greedy"
+
+var strategies = map[string]Strategy{}
+
+// tuned holds the fewest snakes a game needs for each strategy registered
+// with RegisterTunedStrategy to read Params.
+var tuned = map[string]int{}
+
+// RegisterStrategy makes a strategy selectable by name through $STRATEGY or
+// the /{name}/move path.
+func RegisterStrategy(name string, strategy Strategy) {
+	strategies[name] = strategy
+}
+
+// RegisterTunedStrategy registers a strategy that reads Params in games of
+// minSnakes or more snakes, so that tune can search for better ones.
+func RegisterTunedStrategy(name string, strategy Strategy, minSnakes int) {
+	RegisterStrategy(name, strategy)
+	tuned[name] = minSnakes
+}
+
+func lookupStrategy(name string) (Strategy, bool) {
+	strategy, ok := strategies[name]
+	return strategy, ok
+}
+
+// usesParams reports whether the named strategy reads Params, and the
+// fewest snakes a game needs for it to.
+func usesParams(name string) (minSnakes int, ok bool) {
+	minSnakes, ok = tuned[name]
+	return minSnakes, ok
+}
+
+func strategyNames() []string {
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// splitStrategyPath splits "/name/action" into its two parts.
+func splitStrategyPath(path string) (name string, action string) {
+	parts := strings.SplitN(strings.Trim(path, "/"), "/", 2)
+	name = parts[0]
+	if len(parts) == 2 {
+		action = parts[1]
+	}
+	return name, action
+}
+
+// selectStrategy resolves the strategy for a request path: the one of the
+// snake profile it is for, falling back to DEFAULT_STRATEGY.
+func selectStrategy(path string) (string, Strategy) {
+	_, profile := profileFor(path)
+	if strategy, ok := lookupStrategy(profile.Strategy); ok {
+		return profile.Strategy, strategy
+	}
+	return DEFAULT_STRATEGY, strategies[DEFAULT_STRATEGY]
+}
+
+// shouldAttack goes after enemies when we are healthy, longer than every
+// other snake, and hold enough territory to turn around in. A snake that
+// is being squeezed should take space and food instead of chasing.
+func shouldAttack(data *TurnData) bool {
+	p := data.params
+	if data.mysnake.HealthPoints <= p.AttackHealth {
+		return false
+	}
+	for _, s := range data.req.Snakes {
+		if s.ID != data.mysnake.ID && len(s.Coords)+p.AttackMargin > len(data.mysnake.Coords) {
+			return false
+		}
+	}
+	room := float64(territory(data).Counts[data.mysnake.ID])
+	return room >= p.AttackTerritory*float64(len(data.mysnake.Coords))
+}
+
+// greedyMove heads for food or enemies, and switches to a minimax search
+// once the game is down to us and one opponent.
+func greedyMove(data *TurnData) (Dir, Diagnostics, error) {
+	if len(data.req.Snakes) == 2 {
+		return minimaxMove(data)
+	}
+	attack := shouldAttack(data)
+	diagnostics := Diagnostics{"attack": attack, "territory": territory(data).Counts[data.mysnake.ID]}
+	if attack {
+		return findEnemy(data), diagnostics, nil
+	}
+	return findFood(data), diagnostics, nil
+}
+
+func bfsMove(data *TurnData) (Dir, Diagnostics, error) {
+	attack := shouldAttack(data)
+	return bfs(data, attack), Diagnostics{"attack": attack}, nil
+}
+
+func init() {
+	// greedy leaves 1v1 games to minimax, which doesn't read Params.
+	RegisterTunedStrategy(DEFAULT_STRATEGY, StrategyFunc(greedyMove), 3)
+	RegisterTunedStrategy("bfs", StrategyFunc(bfsMove), 2)
+	RegisterStrategy("food", StrategyFunc(func(data *TurnData) (Dir, Diagnostics, error) {
+		return findFood(data), nil, nil
+	}))
+	RegisterStrategy("attack", StrategyFunc(func(data *TurnData) (Dir, Diagnostics, error) {
+		return findEnemy(data), nil, nil
+	}))
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// survivalMove is the move of last resort, for when the request can't be
+// trusted or the strategies blew up on it. It reads nothing but the raw
+// request, so it works on whatever decoded: it stays on the board, off
+// every body and away from our own neck, and failing that at least off
+// the walls and the neck.
+func survivalMove(req *MoveRequest) Dir {
+	if req == nil {
+		return UP
+	}
+	me := getSnake(req, req.You)
+	if len(me.Coords) == 0 && req.Me != nil {
+		me = *req.Me
+	}
+	if len(me.Coords) == 0 {
+		return UP
+	}
+
+	// The rules need every snake to have a body, ours first, and the tail
+	// of one that has just eaten stacked the way they stack it.
+	state := &MoveRequest{Width: req.Width, Height: req.Height}
+	for i, snake := range append([]Snake{me}, req.Snakes...) {
+		if len(snake.Coords) == 0 || i > 0 && snake.ID == me.ID {
+			continue
+		}
+		snake.Coords = append([]Point(nil), snake.Coords...)
+		if tailStays(&snake) && !stackedTail(&snake) {
+			snake.Coords = append(snake.Coords, snake.Coords[len(snake.Coords)-1])
+		}
+		state.Snakes = append(state.Snakes, snake)
+	}
+
+	head := me.Coords[0]
+	best, bestScore := UP, -1
+	for dir := UP; dir < num_dirs; dir++ {
+		p := head.Step(dir)
+		score := 0
+		if inBounds(req, p) && (len(me.Coords) < 2 || p != me.Coords[1]) {
+			score = 1
+			if !moveBlocked(state, me.ID, dir) {
+				score = 2
+			}
+		}
+		if score > bestScore {
+			best, bestScore = dir, score
+		}
+	}
+	return best
+}
+
+// moveWriter remembers whether a response went out, so a panic after it
+// did doesn't answer twice.
+type moveWriter struct {
+	http.ResponseWriter
+	wrote bool
+}
+
+func (w *moveWriter) WriteHeader(status int) {
+	w.wrote = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *moveWriter) Write(b []byte) (int, error) {
+	w.wrote = true
+	return w.ResponseWriter.Write(b)
+}
+
+// recoverMove wraps a move handler so that a panic anywhere in it is
+// logged with the request that caused it and still answered with a
+// survivalMove instead of dropping the connection.
+func recoverMove(next http.HandlerFunc) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		body, _ := readBody(req)
+		w := &moveWriter{ResponseWriter: res}
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			log.Printf("Move handler panicked: %v\n%s", r, body)
+			if w.wrote {
+				return
+			}
+			data, _ := DecodeMoveRequest(body)
+			response := NewMoveResponse(data.API, directions[survivalMove(data)], toStringPointer("oops!"))
+			respond(res, response)
+			snake, _ := profileFor(req.URL.Path)
+			recorder.Record(Record{
+				Type:     "move",
+				GameId:   data.GameId,
+				Snake:    snake,
+				Time:     time.Now().UTC(),
+				Request:  body,
+				Response: response,
+				Error:    fmt.Sprintf("panic: %v", r),
+			})
+		}()
+		next(w, req)
+	}
+}
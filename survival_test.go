@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	assert "gopkg.in/go-playground/assert.v1"
+)
+
+func TestSurvivalMove(t *testing.T) {
+	// Up and left are walls, right is our neck.
+	req, err := ParseBoard(`
+		Aa0
+		...
+		A: me health=90
+	`)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, survivalMove(req), DOWN)
+
+	// Their tail moves out of the way...
+	board := `
+		1A0
+		bB.
+		A: me health=90
+	`
+	req, err = ParseBoard(board + "\t\tB: you health=90\n")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, survivalMove(req), LEFT)
+
+	// ...unless they just ate. With nowhere safe, any body beats the wall
+	// and our neck.
+	req, err = ParseBoard(board + "\t\tB: you health=100\n")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, survivalMove(req), DOWN)
+
+	// Without a snake of ours there is nothing to go on, but no panic.
+	req.You = "ghost"
+	assert.Equal(t, survivalMove(req), UP)
+	assert.Equal(t, survivalMove(nil), UP)
+}
+
+// In the corner at (2, 0) of a 3x2 board, with the neck to the left.
+const cornered = `{"game_id": "g", "width": 3, "height": 2, "you": "me",
+	"snakes": [{"id": "me", "health_points": 90, "coords": [[2, 0], [1, 0]]}]`
+
+func postMove(handler http.HandlerFunc, body string) (*httptest.ResponseRecorder, MoveResponse) {
+	res := httptest.NewRecorder()
+	handler(res, httptest.NewRequest("POST", "/move", strings.NewReader(body)))
+	response := MoveResponse{}
+	json.Unmarshal(res.Body.Bytes(), &response)
+	return res, response
+}
+
+// recordMoves runs f with a recorder on and returns what it recorded for
+// game "g".
+func recordMoves(t *testing.T, f func()) []Record {
+	dir, err := ioutil.TempDir("", "games")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	r, err := NewRecorder(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder = r
+	defer func() { recorder = nil }()
+	f()
+	r.Close()
+	records, err := ReadRecords(r.Path("g", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+func TestBadMoveRequest(t *testing.T) {
+	records := recordMoves(t, func() {
+		// The turn doesn't decode, the board still does.
+		_, response := postMove(recoverMove(handleMove), cornered+`, "turn": "first"}`)
+		assert.Equal(t, response.Move, "down")
+
+		_, response = postMove(recoverMove(handleMove), strings.Replace(cornered, `"you": "me"`, `"you": "ghost"`, 1)+"}")
+		assert.Equal(t, response.Move, "up")
+	})
+	// Marked, so replay leaves them alone.
+	assert.Equal(t, len(records), 2)
+	assert.Equal(t, strings.Contains(records[0].Error, "turn"), true)
+	assert.Equal(t, records[1].Error, `snake "ghost" is not on the board`)
+}
+
+func TestRecoverMove(t *testing.T) {
+	var res *httptest.ResponseRecorder
+	var response MoveResponse
+	records := recordMoves(t, func() {
+		res, response = postMove(recoverMove(func(res http.ResponseWriter, req *http.Request) {
+			panic("boom")
+		}), cornered+"}")
+	})
+	assert.Equal(t, res.Code, http.StatusOK)
+	assert.Equal(t, response.Move, "down")
+	assert.Equal(t, len(records), 1)
+	assert.Equal(t, records[0].Error, "panic: boom")
+	move, _ := records[0].Move()
+	assert.Equal(t, move, DOWN)
+
+	// An answer that already went out is left alone.
+	res, response = postMove(recoverMove(func(res http.ResponseWriter, req *http.Request) {
+		respond(res, MoveResponse{Move: "left"})
+		panic("boom")
+	}), cornered+"}")
+	assert.Equal(t, response.Move, "left")
+	assert.Equal(t, strings.Count(res.Body.String(), "move"), 1)
+}
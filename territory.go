@@ -0,0 +1,76 @@
+package main
+
+// Territory splits the free cells of the board between the snakes by who
+// can get to each one first.
+type Territory struct {
+	Owner     map[Point]string // "" for contested cells
+	Counts    map[string]int   // cells owned per snake id
+	Contested int              // cells two equally long snakes reach at once
+}
+
+// territory runs a BFS from every head at once. A cell goes to the snake
+// that reaches it first; when several get there on the same turn the
+// longest takes it, and if they are equally long it is contested and
+// nobody expands from it. Bodies block until their tail has moved past,
+// as in reachable. The result is cached on data.
+func territory(data *TurnData) *Territory {
+	if data.voronoi != nil {
+		return data.voronoi
+	}
+	t := &Territory{Owner: map[Point]string{}, Counts: map[string]int{}}
+
+	frontiers := map[string][]Point{}
+	for _, snake := range data.req.Snakes {
+		t.Counts[snake.ID] = 0
+		if len(snake.Coords) > 0 && data.board.Contains(snake.Coords[0]) {
+			frontiers[snake.ID] = []Point{snake.Coords[0]}
+		}
+	}
+	seen := map[Point]bool{}
+	for id := range frontiers {
+		seen[frontiers[id][0]] = true
+	}
+
+	for turn := 1; len(frontiers) > 0 && !data.timeUp(); turn++ {
+		claims := map[Point][]string{}
+		for id, frontier := range frontiers {
+			for _, p := range frontier {
+				for dir := UP; dir < num_dirs; dir++ {
+					n := p.Step(dir)
+					if seen[n] || !freeAt(data, n, turn) {
+						continue
+					}
+					if c := claims[n]; len(c) == 0 || c[len(c)-1] != id {
+						claims[n] = append(c, id)
+					}
+				}
+			}
+		}
+
+		next := map[string][]Point{}
+		for p, ids := range claims {
+			seen[p] = true
+			winner := ids[0]
+			tied := false
+			for _, id := range ids[1:] {
+				switch mine, theirs := data.board.Length(id), data.board.Length(winner); {
+				case mine > theirs:
+					winner, tied = id, false
+				case mine == theirs:
+					tied = true
+				}
+			}
+			if tied {
+				t.Owner[p] = ""
+				t.Contested++
+				continue
+			}
+			t.Owner[p] = winner
+			t.Counts[winner]++
+			next[winner] = append(next[winner], p)
+		}
+		frontiers = next
+	}
+	data.voronoi = t
+	return t
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	assert "gopkg.in/go-playground/assert.v1"
+)
+
+func TestTerritory(t *testing.T) {
+	board := `
+		A...B
+		a...b
+		0...1
+		A: me health=90
+		B: them health=90
+	`
+	req, err := ParseBoard(board)
+	assert.Equal(t, err, nil)
+	regions := territory(NewTurnData(req, time.Time{}))
+	assert.Equal(t, regions.Counts, map[string]int{"me": 5, "them": 5})
+	assert.Equal(t, regions.Contested, 3)
+	assert.Equal(t, regions.Owner[Point{2, 1}], "")
+	assert.Equal(t, regions.Owner[Point{0, 2}], "me")
+
+	// The longer snake wins every tie.
+	req, err = ParseBoard(board + "\t\tB: them length=4\n")
+	assert.Equal(t, err, nil)
+	regions = territory(NewTurnData(req, time.Time{}))
+	assert.Equal(t, regions.Counts, map[string]int{"me": 5, "them": 8})
+	assert.Equal(t, regions.Contested, 0)
+}
+
+func TestShouldAttack(t *testing.T) {
+	req, err := ParseBoard(`
+		.......
+		.Aaaa0.
+		.......
+		.......
+		.B1....
+		.......
+		.......
+		A: me health=90
+		B: them health=90
+	`)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, shouldAttack(NewTurnData(req, time.Time{})), true)
+
+	req.Snakes[0].HealthPoints = 20
+	assert.Equal(t, shouldAttack(NewTurnData(req, time.Time{})), false)
+
+	// Longer and healthy, but boxed into a corner by the other snake.
+	req, err = ParseBoard(`
+		Aaaa0.
+		.B1...
+		A: me health=90
+		B: them health=90
+	`)
+	assert.Equal(t, err, nil)
+	data := NewTurnData(req, time.Time{})
+	assert.Equal(t, territory(data).Counts["me"], 1)
+	assert.Equal(t, shouldAttack(data), false)
+}
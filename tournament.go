@@ -0,0 +1,488 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ELO_INITIAL = 1500
+	ELO_K       = 32
+)
+
+// Rating is one entrant's record in the results file.
+type Rating struct {
+	Elo    float64 `json:"elo"`
+	Games  int     `json:"games"`
+	Wins   int     `json:"wins"`
+	Losses int     `json:"losses"`
+	Draws  int     `json:"draws"`
+}
+
+// Standings are the ratings kept in the results file across tournaments,
+// keyed by entrant label.
+type Standings struct {
+	Ratings map[string]*Rating `json:"ratings"`
+	Games   int                `json:"games"`
+}
+
+// loadStandings reads the results file, or starts afresh if there is none.
+func loadStandings(path string) (*Standings, error) {
+	standings := &Standings{Ratings: map[string]*Rating{}}
+	body, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return standings, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(body, standings); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if standings.Ratings == nil {
+		standings.Ratings = map[string]*Rating{}
+	}
+	return standings, nil
+}
+
+// Save writes the standings through a temporary file, so an interrupted
+// run doesn't leave half a results file.
+func (s *Standings) Save(path string) error {
+	body, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, append(body, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s *Standings) rating(label string) *Rating {
+	r, ok := s.Ratings[label]
+	if !ok {
+		r = &Rating{Elo: ELO_INITIAL}
+		s.Ratings[label] = r
+	}
+	return r
+}
+
+// Record updates both ratings after a game; score is a's result: 1 for a
+// win, 0.5 for a draw and 0 for a loss.
+func (s *Standings) Record(a, b string, score float64) {
+	places := []int{0, 0}
+	switch score {
+	case 1:
+		places[1] = 1
+	case 0:
+		places[0] = 1
+	}
+	s.RecordGame([]string{a, b}, places)
+}
+
+// RecordGame updates the ratings of everyone in a game from their places,
+// as from finishingPlaces. Every two snakes in it count as a game between
+// them, rated from before the game at ELO_K shared out over a snake's
+// opponents. Only an outright first place is a win and a shared one a
+// draw.
+func (s *Standings) RecordGame(labels []string, places []int) {
+	k := ELO_K / float64(len(labels)-1)
+	change := make([]float64, len(labels))
+	for i := range labels {
+		for j := i + 1; j < len(labels); j++ {
+			ri, rj := s.rating(labels[i]), s.rating(labels[j])
+			score := 0.5
+			if places[i] < places[j] {
+				score = 1
+			} else if places[i] > places[j] {
+				score = 0
+			}
+			expected := 1 / (1 + math.Pow(10, (rj.Elo-ri.Elo)/400))
+			change[i] += k * (score - expected)
+			change[j] -= k * (score - expected)
+		}
+	}
+
+	first := 0
+	for _, place := range places {
+		if place == 0 {
+			first++
+		}
+	}
+	for i, label := range labels {
+		r := s.rating(label)
+		r.Elo += change[i]
+		r.Games++
+		switch {
+		case places[i] > 0:
+			r.Losses++
+		case first == 1:
+			r.Wins++
+		default:
+			r.Draws++
+		}
+	}
+	s.Games++
+}
+
+// Entrant is a snake in a tournament: a registered strategy or a snake
+// server, under the label it is rated as.
+type Entrant struct {
+	Label string
+	Spec  string
+}
+
+// parseEntrants reads "label=spec" or plain "spec" entries.
+func parseEntrants(list string) ([]Entrant, error) {
+	entrants := []Entrant{}
+	seen := map[string]bool{}
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		entrant := Entrant{Label: item, Spec: item}
+		if i := strings.Index(item, "="); i > 0 && !isSnakeURL(item[:i]) {
+			entrant = Entrant{Label: item[:i], Spec: item[i+1:]}
+		}
+		if seen[entrant.Label] {
+			return nil, fmt.Errorf("%q entered twice", entrant.Label)
+		}
+		seen[entrant.Label] = true
+		entrants = append(entrants, entrant)
+	}
+	if len(entrants) < 2 {
+		return nil, fmt.Errorf("a tournament needs at least two snakes")
+	}
+	return entrants, nil
+}
+
+// roundRobin puts every group of size entrants together once.
+func roundRobin(n, size int) [][]int {
+	groups := [][]int{}
+	var add func(group []int, next int)
+	add = func(group []int, next int) {
+		if len(group) == size {
+			groups = append(groups, append([]int(nil), group...))
+			return
+		}
+		for i := next; i < n; i++ {
+			add(append(group, i), i+1)
+		}
+	}
+	add(nil, 0)
+	return groups
+}
+
+// swissGroups sorts the entrants by rating and fills each group of size
+// with the next best rated ones that haven't played anyone in it yet in
+// this tournament, going by played, and only with ones that have once
+// there is no one else left. When the entrants don't divide into groups,
+// the lowest rated of those who have sat out fewest rounds so far, going
+// by byes, sit this one out.
+func swissGroups(entrants []Entrant, standings *Standings, played map[[2]int]bool, byes map[int]int, size int) [][]int {
+	order := make([]int, len(entrants))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return standings.rating(entrants[order[a]].Label).Elo > standings.rating(entrants[order[b]].Label).Elo
+	})
+	for len(order)%size != 0 {
+		out := len(order) - 1
+		for i := out - 1; i >= 0; i-- {
+			if byes[order[i]] < byes[order[out]] {
+				out = i
+			}
+		}
+		order = append(order[:out:out], order[out+1:]...)
+	}
+
+	groups := [][]int{}
+	grouped := map[int]bool{}
+	for _, a := range order {
+		if grouped[a] {
+			continue
+		}
+		group := []int{a}
+		grouped[a] = true
+		for len(group) < size {
+			next := -1
+			for _, b := range order {
+				if grouped[b] {
+					continue
+				}
+				if next < 0 {
+					next = b
+				}
+				if !playedAny(played, b, group) {
+					next = b
+					break
+				}
+			}
+			group = append(group, next)
+			grouped[next] = true
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// playedAny reports whether b has played anyone in group.
+func playedAny(played map[[2]int]bool, b int, group []int) bool {
+	for _, a := range group {
+		if played[pairing(a, b)] {
+			return true
+		}
+	}
+	return false
+}
+
+// pairing is the key of a pair of entrants in either order.
+func pairing(a, b int) [2]int {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]int{a, b}
+}
+
+// match is one game of a tournament round.
+type match struct {
+	entrants []Entrant // in seat order
+	config   GameConfig
+	places   []int // the entrants' finishingPlaces
+	turns    int
+	err      error
+}
+
+// play runs the match with fresh players, so no session state leaks from
+// one game to the next.
+func (m *match) play(api APIVersion, timeout time.Duration, nodes int) {
+	players := make([]Player, len(m.entrants))
+	for i, e := range m.entrants {
+		player, err := newPlayer(e.Spec, api, timeout, nodes)
+		if err != nil {
+			m.err = err
+			return
+		}
+		players[i] = player
+	}
+	result := NewGame(m.config, players).Run()
+	m.turns = result.Turns
+	m.places = finishingPlaces(result, len(players))
+}
+
+// finishingPlaces ranks the seats of a game, 0 being first: the survivors
+// share first place and the rest follow, the last eliminated first. Snakes
+// eliminated on the same turn share a place.
+func finishingPlaces(result GameResult, seats int) []int {
+	lasted := make([]int, seats)
+	seat := map[string]int{}
+	for i := range lasted {
+		lasted[i] = result.Turns + 1
+		seat[fmt.Sprintf("snake-%d", i)] = i
+	}
+	for _, e := range result.Eliminated {
+		if i, ok := seat[e.Snake]; ok {
+			lasted[i] = e.Turn
+		}
+	}
+	places := make([]int, seats)
+	for i := range places {
+		for j := range lasted {
+			if lasted[j] > lasted[i] {
+				places[i]++
+			}
+		}
+	}
+	return places
+}
+
+// outcome describes how the match went: "a beat b" between two snakes, and
+// the finishing order, as in "a > b = c", between more.
+func (m *match) outcome() string {
+	if len(m.entrants) == 2 {
+		score := 0.5
+		if m.places[0] < m.places[1] {
+			score = 1
+		} else if m.places[0] > m.places[1] {
+			score = 0
+		}
+		return fmt.Sprintf("%s %s %s", m.entrants[0].Label, scoreName(score), m.entrants[1].Label)
+	}
+	order := make([]int, len(m.entrants))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return m.places[order[a]] < m.places[order[b]] })
+	out := m.entrants[order[0]].Label
+	for k, i := range order[1:] {
+		sep := " > "
+		if m.places[i] == m.places[order[k]] {
+			sep = " = "
+		}
+		out += sep + m.entrants[i].Label
+	}
+	return out
+}
+
+// runTournament plays rounds of games between strategies and snake servers
+// on every core and keeps Elo ratings in a results file. Games are 1v1
+// unless -snakes-per-game says otherwise.
+//
+//	battlesnake-go tournament -snakes old=http://localhost:9000,greedy,minimax -rounds 10
+func runTournament(args []string) error {
+	flags := flag.NewFlagSet("tournament", flag.ExitOnError)
+	snakes := flags.String("snakes", strings.Join(strategyNames(), ","), "comma separated strategy names or snake server URLs, each optionally as label=snake")
+	format := flags.String("format", "round-robin", "pairing of each round: round-robin or swiss")
+	rounds := flags.Int("rounds", 1, "number of rounds to play")
+	perGame := flags.Int("snakes-per-game", 2, "snakes in each game")
+	parallel := flags.Int("parallel", runtime.NumCPU(), "games to play at once")
+	seed := flags.Int64("seed", 1, "seed of the first game, incremented for each following game")
+	width := flags.Int("width", DefaultGameConfig.Width, "board width")
+	height := flags.Int("height", DefaultGameConfig.Height, "board height")
+	maxTurns := flags.Int("max-turns", 500, "call a game a draw after this many turns (0 for no limit)")
+	results := flags.String("results", "ratings.json", "file the ratings are read from and saved to")
+	timeout := flags.Duration("timeout", 500*time.Millisecond, "how long snake servers get to answer")
+	nodes := flags.Int("nodes", 1000, "minimax nodes or MCTS iterations strategies get per move (0 to search against the clock)")
+	api := flags.String("api", "v1", "protocol spoken to snake servers: v1 or 2017")
+	flags.Parse(args)
+
+	protocol := APIv1
+	switch *api {
+	case "v1":
+	case "2017":
+		protocol = API2017
+	default:
+		return fmt.Errorf("unknown api %q", *api)
+	}
+	if *format != "round-robin" && *format != "swiss" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	if *parallel < 1 {
+		*parallel = 1
+	}
+
+	entrants, err := parseEntrants(*snakes)
+	if err != nil {
+		return err
+	}
+	if *perGame < 2 || *perGame > len(entrants) {
+		return fmt.Errorf("-snakes-per-game must be between 2 and the %d snakes entered", len(entrants))
+	}
+	for _, e := range entrants {
+		if _, err := newPlayer(e.Spec, protocol, *timeout, *nodes); err != nil {
+			return err
+		}
+	}
+	standings, err := loadStandings(*results)
+	if err != nil {
+		return err
+	}
+
+	game := 0
+	played := map[[2]int]bool{}
+	byes := map[int]int{}
+	for round := 0; round < *rounds; round++ {
+		groups := roundRobin(len(entrants), *perGame)
+		if *format == "swiss" {
+			groups = swissGroups(entrants, standings, played, byes, *perGame)
+		}
+		playing := map[int]bool{}
+
+		matches := make([]*match, len(groups))
+		for i, group := range groups {
+			// Take turns on the starting spots.
+			seats := make([]Entrant, len(group))
+			for k := range group {
+				seats[k] = entrants[group[(k+round)%len(group)]]
+			}
+			gameConfig := DefaultGameConfig
+			gameConfig.Width, gameConfig.Height = *width, *height
+			gameConfig.Seed = *seed + int64(game)
+			gameConfig.MaxTurns = *maxTurns
+			matches[i] = &match{entrants: seats, config: gameConfig}
+			for k, a := range group {
+				for _, b := range group[k+1:] {
+					played[pairing(a, b)] = true
+				}
+				playing[a] = true
+			}
+			game++
+		}
+		for i := range entrants {
+			if !playing[i] {
+				byes[i]++
+			}
+		}
+
+		work := make(chan *match)
+		var wg sync.WaitGroup
+		for w := 0; w < *parallel; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for m := range work {
+					m.play(protocol, *timeout, *nodes)
+				}
+			}()
+		}
+		for _, m := range matches {
+			work <- m
+		}
+		close(work)
+		wg.Wait()
+
+		// Rate in a fixed order so that, with strategies on a fixed -nodes
+		// budget, a rerun with the same seeds agrees.
+		for _, m := range matches {
+			if m.err != nil {
+				return m.err
+			}
+			labels := make([]string, len(m.entrants))
+			for k, e := range m.entrants {
+				labels[k] = e.Label
+			}
+			standings.RecordGame(labels, m.places)
+			fmt.Fprintf(os.Stderr, "round %d (seed %d): %s after %d turns\n",
+				round+1, m.config.Seed, m.outcome(), m.turns)
+		}
+		if err := standings.Save(*results); err != nil {
+			return err
+		}
+	}
+
+	printStandings(standings, entrants)
+	return nil
+}
+
+func scoreName(score float64) string {
+	switch score {
+	case 1:
+		return "beat"
+	case 0:
+		return "lost to"
+	}
+	return "drew with"
+}
+
+// printStandings lists this tournament's entrants, best rated first.
+func printStandings(standings *Standings, entrants []Entrant) {
+	sort.SliceStable(entrants, func(a, b int) bool {
+		return standings.rating(entrants[a].Label).Elo > standings.rating(entrants[b].Label).Elo
+	})
+	fmt.Printf("%-24s %6s %6s %6s %6s %6s\n", "snake", "elo", "games", "wins", "losses", "draws")
+	for _, e := range entrants {
+		r := standings.rating(e.Label)
+		fmt.Printf("%-24s %6.0f %6d %6d %6d %6d\n", e.Label, r.Elo, r.Games, r.Wins, r.Losses, r.Draws)
+	}
+}
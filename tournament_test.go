@@ -0,0 +1,164 @@
+package main
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	assert "gopkg.in/go-playground/assert.v1"
+)
+
+func TestStandings(t *testing.T) {
+	s := &Standings{Ratings: map[string]*Rating{}}
+	s.Record("a", "b", 1)
+	assert.Equal(t, *s.Ratings["a"], Rating{Elo: ELO_INITIAL + ELO_K/2, Games: 1, Wins: 1})
+	assert.Equal(t, *s.Ratings["b"], Rating{Elo: ELO_INITIAL - ELO_K/2, Games: 1, Losses: 1})
+
+	// Beating a weaker snake is worth less, and a draw with it costs.
+	s.Record("a", "b", 1)
+	assert.Equal(t, s.Ratings["a"].Elo-ELO_INITIAL < ELO_K, true)
+	before := s.Ratings["a"].Elo
+	s.Record("b", "a", 0.5)
+	assert.Equal(t, s.Ratings["a"].Elo < before, true)
+	assert.Equal(t, s.Ratings["a"].Draws, 1)
+	assert.Equal(t, math.Abs(s.Ratings["a"].Elo+s.Ratings["b"].Elo-2*ELO_INITIAL) < 1e-9, true)
+	assert.Equal(t, s.Games, 3)
+
+	// In bigger games every two snakes count, and a shared first place is
+	// a draw for those sharing it.
+	multi := &Standings{Ratings: map[string]*Rating{}}
+	multi.RecordGame([]string{"a", "b", "c", "d"}, []int{2, 0, 0, 3})
+	assert.Equal(t, *multi.Ratings["b"], Rating{Elo: ELO_INITIAL + ELO_K/3.0, Games: 1, Draws: 1})
+	assert.Equal(t, *multi.Ratings["c"], Rating{Elo: ELO_INITIAL + ELO_K/3.0, Games: 1, Draws: 1})
+	assert.Equal(t, *multi.Ratings["a"], Rating{Elo: ELO_INITIAL - ELO_K/6.0, Games: 1, Losses: 1})
+	assert.Equal(t, *multi.Ratings["d"], Rating{Elo: ELO_INITIAL - ELO_K/2.0, Games: 1, Losses: 1})
+	assert.Equal(t, multi.Games, 1)
+
+	dir, err := ioutil.TempDir("", "tournament")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "ratings.json")
+	fresh, err := loadStandings(path)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(fresh.Ratings), 0)
+	assert.Equal(t, s.Save(path), nil)
+	loaded, err := loadStandings(path)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, loaded, s)
+}
+
+func TestPairings(t *testing.T) {
+	entrants, err := parseEntrants("greedy, old=http://localhost:9000/x?a=b, http://localhost:9001")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, entrants, []Entrant{
+		{Label: "greedy", Spec: "greedy"},
+		{Label: "old", Spec: "http://localhost:9000/x?a=b"},
+		{Label: "http://localhost:9001", Spec: "http://localhost:9001"},
+	})
+	_, err = parseEntrants("greedy")
+	assert.NotEqual(t, err, nil)
+	_, err = parseEntrants("greedy,greedy")
+	assert.NotEqual(t, err, nil)
+
+	assert.Equal(t, roundRobin(3, 2), [][]int{{0, 1}, {0, 2}, {1, 2}})
+	assert.Equal(t, roundRobin(4, 3), [][]int{{0, 1, 2}, {0, 1, 3}, {0, 2, 3}, {1, 2, 3}})
+
+	s := &Standings{Ratings: map[string]*Rating{
+		"a": {Elo: 1400}, "b": {Elo: 1600}, "c": {Elo: 1500}, "d": {Elo: 1700},
+	}}
+	swiss := []Entrant{{Label: "a"}, {Label: "b"}, {Label: "c"}, {Label: "d"}}
+	assert.Equal(t, swissGroups(swiss, s, nil, nil, 2), [][]int{{3, 1}, {2, 0}})
+
+	// No rematches while there is anyone else to play...
+	played := map[[2]int]bool{pairing(3, 1): true}
+	assert.Equal(t, swissGroups(swiss, s, played, nil, 2), [][]int{{3, 2}, {1, 0}})
+	// ...and the best rated one still left when there isn't.
+	played[pairing(3, 2)], played[pairing(3, 0)] = true, true
+	assert.Equal(t, swissGroups(swiss, s, played, nil, 2), [][]int{{3, 1}, {2, 0}})
+
+	// With an odd number the bye goes round, lowest rated first.
+	assert.Equal(t, swissGroups(swiss[:3], s, nil, nil, 2), [][]int{{1, 2}})
+	byes := map[int]int{0: 1}
+	assert.Equal(t, swissGroups(swiss[:3], s, nil, byes, 2), [][]int{{1, 0}})
+	byes[2] = 1
+	assert.Equal(t, swissGroups(swiss[:3], s, nil, byes, 2), [][]int{{2, 0}})
+
+	// Bigger groups fill up the same way: c sits out as a already has, and
+	// b, who has played d, comes in last.
+	assert.Equal(t, swissGroups(swiss, s, nil, nil, 3), [][]int{{3, 1, 2}})
+	played = map[[2]int]bool{pairing(3, 1): true}
+	assert.Equal(t, swissGroups(swiss, s, played, map[int]int{0: 1}, 3), [][]int{{3, 0, 1}})
+}
+
+func TestFinishingPlaces(t *testing.T) {
+	result := GameResult{Winner: "snake-2", Turns: 40, Eliminated: []Elimination{
+		{Snake: "snake-0", Turn: 12}, {Snake: "snake-3", Turn: 30}, {Snake: "snake-1", Turn: 12},
+	}}
+	assert.Equal(t, finishingPlaces(result, 4), []int{2, 2, 0, 1})
+
+	// Everyone left at the turn limit shares first place.
+	result = GameResult{Turns: 50, Eliminated: []Elimination{{Snake: "snake-1", Turn: 20}}}
+	assert.Equal(t, finishingPlaces(result, 3), []int{0, 2, 0})
+
+	m := &match{entrants: []Entrant{{Label: "a"}, {Label: "b"}, {Label: "c"}}, places: []int{2, 0, 0}}
+	assert.Equal(t, m.outcome(), "b = c > a")
+	m = &match{entrants: m.entrants[:2], places: []int{1, 0}}
+	assert.Equal(t, m.outcome(), "a lost to b")
+}
+
+func TestMatchReplays(t *testing.T) {
+	// On a fixed budget the searches play the same game every time.
+	gameConfig := DefaultGameConfig
+	gameConfig.Width, gameConfig.Height = 7, 7
+	gameConfig.Seed = 1
+	gameConfig.MaxTurns = 30
+	var first []TurnLog
+	for i := 0; i < 3; i++ {
+		a, err := newPlayer("minimax", APIv1, time.Second, 200)
+		assert.Equal(t, err, nil)
+		b, err := newPlayer("mcts", APIv1, time.Second, 200)
+		assert.Equal(t, err, nil)
+		g := NewGame(gameConfig, []Player{a, b})
+		g.Run()
+		if i == 0 {
+			first = g.Log
+		}
+		assert.Equal(t, g.Log, first)
+	}
+}
+
+func TestRunTournament(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tournament")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "ratings.json")
+
+	args := []string{"-snakes", "food,attack,hungry=food", "-rounds", "2", "-width", "7", "-height", "7", "-max-turns", "50", "-results", path}
+	assert.Equal(t, runTournament(args), nil)
+	s, err := loadStandings(path)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, s.Games, 6)
+	assert.Equal(t, s.Ratings["hungry"].Games, 4)
+
+	// Ratings carry over to the next tournament.
+	assert.Equal(t, runTournament(append(args, "-format", "swiss", "-rounds", "1")), nil)
+	s, err = loadStandings(path)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, s.Games, 7)
+
+	// Three snakes to a game, all of them in each round.
+	args = append(args, "-snakes-per-game", "3")
+	assert.Equal(t, runTournament(args), nil)
+	s, err = loadStandings(path)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, s.Games, 7+2)
+	assert.Equal(t, s.Ratings["hungry"].Games, 4+2)
+	assert.NotEqual(t, runTournament(append(args, "-snakes-per-game", "4")), nil)
+}
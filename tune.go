@@ -0,0 +1,227 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// candidate is one parameter set in the tuner's population.
+type candidate struct {
+	params  Params
+	fitness float64
+}
+
+// tuner evolves Params with a genetic algorithm. Every candidate plays the
+// same seeded games against snakes using the baseline parameters, and the
+// fittest are kept and bred into the next generation. With a node budget
+// the searches don't depend on the clock either, so a candidate scores
+// the same however often it is played.
+type tuner struct {
+	strategy string
+	baseline Params
+	snakes   int
+	games    int
+	nodes    int
+	config   GameConfig
+	parallel int
+	rng      *rand.Rand
+}
+
+// gameScore is how well the snake in seat did: 1 for a win, 0.5 for
+// lasting to the turn limit, and otherwise up to 0.5 for how long it
+// lasted.
+func gameScore(result GameResult, seat string) float64 {
+	if result.Winner == seat {
+		return 1
+	}
+	for _, e := range result.Eliminated {
+		if e.Snake == seat {
+			if result.Turns == 0 {
+				return 0
+			}
+			return 0.5 * float64(e.Turn) / float64(result.Turns)
+		}
+	}
+	return 0.5
+}
+
+// evaluate plays the candidate's games for one generation and returns its
+// average score. The candidate moves around the seats from game to game.
+func (t *tuner) evaluate(p Params, generation int) (float64, error) {
+	total := 0.0
+	for g := 0; g < t.games; g++ {
+		seat := g % t.snakes
+		players := make([]Player, t.snakes)
+		for i := range players {
+			own := t.baseline
+			if i == seat {
+				own = p
+			}
+			player, err := NewTunedPlayer(t.strategy, own, t.nodes)
+			if err != nil {
+				return 0, err
+			}
+			players[i] = player
+		}
+		gameConfig := t.config
+		gameConfig.Seed += int64(generation*t.games + g)
+		result := NewGame(gameConfig, players).Run()
+		total += gameScore(result, fmt.Sprintf("snake-%d", seat))
+	}
+	return total / float64(t.games), nil
+}
+
+// evaluateAll scores the population on every core.
+func (t *tuner) evaluateAll(population []*candidate, generation int) error {
+	work := make(chan *candidate)
+	errs := make(chan error, len(population))
+	var wg sync.WaitGroup
+	for w := 0; w < t.parallel; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range work {
+				fitness, err := t.evaluate(c.params, generation)
+				if err != nil {
+					errs <- err
+				}
+				c.fitness = fitness
+			}
+		}()
+	}
+	for _, c := range population {
+		work <- c
+	}
+	close(work)
+	wg.Wait()
+	close(errs)
+	return <-errs
+}
+
+// mutate nudges every field with probability rate by a Gaussian step of a
+// tenth of its range.
+func (t *tuner) mutate(p Params, rate float64) Params {
+	for _, r := range paramRanges {
+		if t.rng.Float64() < rate {
+			r.set(&p, r.clamp(r.get(p)+t.rng.NormFloat64()*(r.max-r.min)/10))
+		}
+	}
+	return p
+}
+
+// crossover takes each field from either parent.
+func (t *tuner) crossover(a, b Params) Params {
+	child := a
+	for _, r := range paramRanges {
+		if t.rng.Intn(2) == 1 {
+			r.set(&child, r.get(b))
+		}
+	}
+	return child
+}
+
+// breed keeps the best quarter of a population sorted best first and fills
+// the rest with mutated children of its better half.
+func (t *tuner) breed(population []*candidate) []*candidate {
+	keep := len(population) / 4
+	if keep < 1 {
+		keep = 1
+	}
+	parents := len(population) / 2
+	if parents < 1 {
+		parents = 1
+	}
+	next := append([]*candidate(nil), population[:keep]...)
+	for len(next) < len(population) {
+		a := population[t.rng.Intn(parents)].params
+		b := population[t.rng.Intn(parents)].params
+		next = append(next, &candidate{params: t.mutate(t.crossover(a, b), 0.5)})
+	}
+	return next
+}
+
+// runTune evolves parameters for a strategy by self-play and writes the
+// best set found to a file the server loads with $PARAMS_FILE.
+//
+//	battlesnake-go tune -strategy greedy -generations 20 -out params.json
+func runTune(args []string) error {
+	flags := flag.NewFlagSet("tune", flag.ExitOnError)
+	strategy := flags.String("strategy", DEFAULT_STRATEGY, "strategy to tune")
+	start := flags.String("start", "", "parameter file to start from and play against (default: the built-in parameters)")
+	out := flags.String("out", "params.json", "file the best parameters are written to after every generation")
+	size := flags.Int("population", 12, "parameter sets per generation")
+	generations := flags.Int("generations", 10, "generations to evolve")
+	games := flags.Int("games", 8, "games each parameter set plays per generation")
+	snakes := flags.Int("snakes", 4, "snakes per game")
+	seed := flags.Int64("seed", 1, "seed for the games and the search")
+	width := flags.Int("width", DefaultGameConfig.Width, "board width")
+	height := flags.Int("height", DefaultGameConfig.Height, "board height")
+	maxTurns := flags.Int("max-turns", 300, "stop a game after this many turns")
+	nodes := flags.Int("nodes", 1000, "minimax nodes or MCTS iterations per move (0 to search against the clock)")
+	timeout := flags.Int("timeout", 500, "milliseconds per move the snakes are told they have with -nodes 0")
+	parallel := flags.Int("parallel", runtime.NumCPU(), "parameter sets to evaluate at once")
+	flags.Parse(args)
+
+	if _, ok := lookupStrategy(*strategy); !ok {
+		return fmt.Errorf("unknown strategy %q", *strategy)
+	}
+	if *size < 2 || *games < 1 || *snakes < 2 {
+		return fmt.Errorf("need a population of at least 2, at least 1 game and 2 snakes")
+	}
+	fewest, ok := usesParams(*strategy)
+	if !ok {
+		return fmt.Errorf("%s doesn't use parameters", *strategy)
+	}
+	if *snakes < fewest {
+		return fmt.Errorf("%s only uses parameters in games of %d or more snakes", *strategy, fewest)
+	}
+	baseline := DefaultParams
+	if *start != "" {
+		var err error
+		if baseline, err = LoadParams(*start); err != nil {
+			return err
+		}
+	}
+	if *parallel < 1 {
+		*parallel = 1
+	}
+
+	gameConfig := DefaultGameConfig
+	gameConfig.Width, gameConfig.Height = *width, *height
+	gameConfig.Seed = *seed
+	gameConfig.MaxTurns = *maxTurns
+	gameConfig.Timeout = *timeout
+	t := &tuner{
+		strategy: *strategy,
+		baseline: baseline,
+		snakes:   *snakes,
+		games:    *games,
+		nodes:    *nodes,
+		config:   gameConfig,
+		parallel: *parallel,
+		rng:      rand.New(rand.NewSource(*seed)),
+	}
+
+	population := []*candidate{{params: baseline}}
+	for len(population) < *size {
+		population = append(population, &candidate{params: t.mutate(baseline, 1)})
+	}
+	for generation := 0; generation < *generations; generation++ {
+		if err := t.evaluateAll(population, generation); err != nil {
+			return err
+		}
+		sort.SliceStable(population, func(a, b int) bool { return population[a].fitness > population[b].fitness })
+		best := population[0]
+		fmt.Fprintf(os.Stderr, "generation %d: best %.3f %+v\n", generation+1, best.fitness, best.params)
+		if err := best.params.Save(*out); err != nil {
+			return err
+		}
+		population = t.breed(population)
+	}
+	return nil
+}